@@ -0,0 +1,55 @@
+package logger
+
+import "time"
+
+// --- Logging with caller-supplied timestamps ---
+
+// DebugAt logs a debug message with structured key-value pairs, stamped with t
+// instead of the current time, e.g. when replaying or backfilling past events.
+// t is used for every timestamp: console, text file, JSON "time" and Sinks.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func DebugAt(t time.Time, msg string, keyvals ...any) {
+	if !isLevelEnabled(DebugLevel) {
+		return
+	}
+	logMessageAt(DebugLevel, 2, t, msg, keyvals)
+}
+
+// InfoAt logs an informational message with structured key-value pairs, stamped
+// with t instead of the current time.
+//
+// Example:
+//
+//	logger.InfoAt(evt.OccurredAt, "order placed", "order", evt.ID)
+//
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func InfoAt(t time.Time, msg string, keyvals ...any) {
+	if !isLevelEnabled(InfoLevel) {
+		return
+	}
+	logMessageAt(InfoLevel, 2, t, msg, keyvals)
+}
+
+// WarnAt logs a warning message with structured key-value pairs, stamped with t
+// instead of the current time.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func WarnAt(t time.Time, msg string, keyvals ...any) {
+	if !isLevelEnabled(WarnLevel) {
+		return
+	}
+	logMessageAt(WarnLevel, 2, t, msg, keyvals)
+}
+
+// ErrorAt logs an error message with structured key-value pairs, stamped with t
+// instead of the current time.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func ErrorAt(t time.Time, msg string, keyvals ...any) {
+	if !isLevelEnabled(ErrorLevel) {
+		return
+	}
+	logMessageAt(ErrorLevel, 2, t, msg, keyvals)
+}
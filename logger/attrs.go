@@ -0,0 +1,78 @@
+package logger
+
+import "log/slog"
+
+// --- slog.Attr logging methods ---
+
+// attrKeyvals flattens attrs into key-value pairs for the field encoder.
+// Values are resolved first, so slog.LogValuers log their LogValue. Group
+// members are expanded with dotted keys ("req.method"), a group with an empty
+// key is inlined, and empty attrs and groups are skipped as slog does.
+func attrKeyvals(prefix string, attrs []slog.Attr, keyvals []any) []any {
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		key := a.Key
+		if prefix != "" && key != "" {
+			key = prefix + "." + key
+		} else if key == "" {
+			key = prefix
+		}
+		if v.Kind() == slog.KindGroup {
+			keyvals = attrKeyvals(key, v.Group(), keyvals)
+			continue
+		}
+		if a.Key == "" {
+			continue
+		}
+		keyvals = append(keyvals, key, v.Any())
+	}
+	return keyvals
+}
+
+// DebugAttrs logs a debug message with slog.Attr fields rendered like DebugKV.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func DebugAttrs(msg string, attrs ...slog.Attr) {
+	if !isLevelEnabled(DebugLevel) {
+		return
+	}
+	logMessage(DebugLevel, 2, msg, attrKeyvals("", attrs, nil))
+}
+
+// InfoAttrs logs an informational message with slog.Attr fields rendered like
+// InfoKV, easing migration of code that already builds attributes.
+//
+// Example:
+//
+//	logger.InfoAttrs("request", slog.String("path", "/"),
+//		slog.Group("resp", slog.Int("status", 200)))
+//	// request path=/ resp.status=200
+//
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func InfoAttrs(msg string, attrs ...slog.Attr) {
+	if !isLevelEnabled(InfoLevel) {
+		return
+	}
+	logMessage(InfoLevel, 2, msg, attrKeyvals("", attrs, nil))
+}
+
+// WarnAttrs logs a warning message with slog.Attr fields rendered like WarnKV.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func WarnAttrs(msg string, attrs ...slog.Attr) {
+	if !isLevelEnabled(WarnLevel) {
+		return
+	}
+	logMessage(WarnLevel, 2, msg, attrKeyvals("", attrs, nil))
+}
+
+// ErrorAttrs logs an error message with slog.Attr fields rendered like ErrorKV.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func ErrorAttrs(msg string, attrs ...slog.Attr) {
+	if !isLevelEnabled(ErrorLevel) {
+		return
+	}
+	logMessage(ErrorLevel, 2, msg, attrKeyvals("", attrs, nil))
+}
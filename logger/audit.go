@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	// auditFile is the open Config.AuditFilePath; nil when audit lines go to stderr.
+	// Guarded by logMutex, like the audit fields below.
+	auditFile     *os.File
+	auditSequence bool
+	auditSeq      uint64
+)
+
+// openAuditFile switches audit output to config.AuditFilePath, closing the
+// previous audit file. Open errors are reported on stderr and leave audit
+// lines on stderr so they are never lost. Must hold logMutex.
+func openAuditFile(config Config) {
+	if auditFile != nil {
+		_ = auditFile.Close()
+		auditFile = nil
+	}
+	auditSequence = config.AuditSequence
+	auditSeq = 0
+	if config.AuditFilePath == "" {
+		return
+	}
+	f, err := openAuditPath(config.AuditFilePath)
+	if err != nil {
+		fmt.Fprintf(outStderr, "failed to open audit file %s: %v\n", config.AuditFilePath, err)
+		return
+	}
+	auditFile = f
+}
+
+// openAuditPath opens path for appending audit lines, creating it if needed.
+func openAuditPath(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+}
+
+// closeAuditFile closes the audit file if one is open. Must hold logMutex.
+func closeAuditFile() error {
+	if auditFile == nil {
+		return nil
+	}
+	err := auditFile.Close()
+	auditFile = nil
+	return err
+}
+
+// Audit records a security audit event with structured key-value pairs.
+// Audit is not a severity level: it ignores Config.Levels, LOGGER_LEVELS,
+// SetLevels and SetLeveler and always writes.
+//
+// Lines go only to Config.AuditFilePath, unbuffered, as
+// "<RFC3339 timestamp> [seq=N] [caller] msg key=value ..."; the sequence number is
+// present with Config.AuditSequence, starts at 1 on every Init, and has no gaps,
+// so deleted lines are detectable. Without an audit file, lines are written
+// to stderr prefixed with "[AUDIT]".
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func Audit(msg string, keyvals ...any) {
+	fields := callFields(keyvals)
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	fields = buildFields(fields)
+	line := messageWithFields(msg, encodeFields(fields))
+	if includeCallerTag {
+		line = fmt.Sprintf("[%s] %s", getCallerInfo(2), line)
+	}
+	if auditSequence {
+		auditSeq++
+		line = "seq=" + strconv.FormatUint(auditSeq, 10) + " " + line
+	}
+	line = nowFunc().Format(time.RFC3339Nano) + " " + line + lineTerminator
+
+	var w io.Writer = auditFile
+	if auditFile == nil {
+		w = outStderr
+		line = "[AUDIT] " + line
+	}
+	_, err := io.WriteString(w, line)
+	reportWriteError(err)
+}
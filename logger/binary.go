@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// frameVersion is the current FormatBinary wire format version.
+const frameVersion = 1
+
+// maxFrameSize bounds the payload length accepted by DecodeFrame so a corrupt
+// length prefix cannot trigger a huge allocation.
+const maxFrameSize = 16 << 20
+
+// ErrInvalidFrame is returned by DecodeFrame for malformed or unsupported frames.
+var ErrInvalidFrame = errors.New("logger: invalid frame")
+
+// binarySink writes records as FormatBinary frames.
+type binarySink struct {
+	w io.Writer
+}
+
+func (s *binarySink) WriteRecord(rec Record) error {
+	_, err := s.w.Write(encodeFrame(rec))
+	return err
+}
+
+// encodeFrame renders rec in the FormatBinary wire format described on DecodeFrame.
+func encodeFrame(rec Record) []byte {
+	buf := make([]byte, 4, 64+len(rec.Message))
+	buf = append(buf, frameVersion, byte(rec.Level))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(rec.Time.UnixNano()))
+	buf = appendFrameString(buf, rec.Caller)
+	buf = appendFrameString(buf, rec.text())
+	buf = binary.AppendUvarint(buf, uint64(len(rec.Fields)))
+	for _, f := range rec.Fields {
+		buf = appendFrameString(buf, f.Key)
+		buf = appendFrameString(buf, formatValue(f.Value))
+	}
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(buf)-4))
+	return buf
+}
+
+func appendFrameString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// DecodeFrame reads one FormatBinary frame from r.
+// It returns io.EOF when r is exhausted at a frame boundary and
+// io.ErrUnexpectedEOF when a frame is truncated.
+//
+// Wire format (all integers big-endian, strings are a uvarint length followed by UTF-8 bytes):
+//
+//	length   uint32   number of bytes that follow
+//	version  uint8    currently 1
+//	level    uint8    Level value
+//	time     int64    Unix time in nanoseconds
+//	caller   string   empty unless caller tagging is enabled
+//	message  string   with an Api status code as a "[200] " prefix
+//	nfields  uvarint
+//	fields   nfields × (key string, value string)
+//
+// Field values are written as they appear in text output, so decoded values are always strings.
+func DecodeFrame(r io.Reader) (Record, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Record{}, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxFrameSize {
+		return Record{}, fmt.Errorf("%w: frame of %d bytes exceeds limit", ErrInvalidFrame, size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return Record{}, err
+	}
+
+	d := frameDecoder{buf: payload}
+	if v := d.byte(); v != frameVersion {
+		if d.err != nil {
+			return Record{}, d.err
+		}
+		return Record{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidFrame, v)
+	}
+	rec := Record{Level: Level(d.byte())}
+	rec.Time = time.Unix(0, int64(d.uint64()))
+	rec.Caller = d.string()
+	rec.Message = d.string()
+	n := d.uvarint()
+	if n > uint64(len(d.buf)) {
+		return Record{}, fmt.Errorf("%w: field count %d exceeds frame", ErrInvalidFrame, n)
+	}
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		key := d.string()
+		value := d.string()
+		rec.Fields = append(rec.Fields, Field{Key: key, Value: value})
+	}
+	if d.err != nil {
+		return Record{}, d.err
+	}
+	return rec, nil
+}
+
+// frameDecoder consumes a frame payload, remembering the first error.
+type frameDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *frameDecoder) fail() {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: truncated payload", ErrInvalidFrame)
+	}
+	d.buf = nil
+}
+
+func (d *frameDecoder) byte() byte {
+	if len(d.buf) < 1 {
+		d.fail()
+		return 0
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *frameDecoder) uint64() uint64 {
+	if len(d.buf) < 8 {
+		d.fail()
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.buf)
+	d.buf = d.buf[8:]
+	return v
+}
+
+func (d *frameDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *frameDecoder) string() string {
+	n := d.uvarint()
+	if n > uint64(len(d.buf)) {
+		d.fail()
+		return ""
+	}
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
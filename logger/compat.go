@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- Drop-in replacements for the standard log package ---
+//
+// These mirror log.Print*, log.Panic* so code can migrate by swapping the
+// package name, then move to the leveled methods over time.
+
+// Print logs at Config.DefaultLevel (INFO by default), formatting like fmt.Sprint.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func Print(v ...any) {
+	level := defaultLevel
+	if !isLevelEnabled(level) {
+		return
+	}
+	logMessage(level, 2, fmt.Sprint(v...), nil)
+}
+
+// Printf logs at Config.DefaultLevel (INFO by default), formatting like fmt.Sprintf.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func Printf(format string, v ...any) {
+	level := defaultLevel
+	if !isLevelEnabled(level) {
+		return
+	}
+	logMessage(level, 2, sprintf(format, v...), nil)
+}
+
+// Println logs at Config.DefaultLevel (INFO by default), formatting like fmt.Sprintln
+// without the trailing newline.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func Println(v ...any) {
+	level := defaultLevel
+	if !isLevelEnabled(level) {
+		return
+	}
+	logMessage(level, 2, sprintln(v...), nil)
+}
+
+// Panic logs a critical message formatted like fmt.Sprint and then panics with it.
+// It panics even when CRIT is disabled.
+// Caller tagging is included when enabled in Init.
+func Panic(v ...any) {
+	msg := fmt.Sprint(v...)
+	if isLevelEnabled(CritLevel) {
+		logMessage(CritLevel, 2, msg, nil)
+	}
+	panic(msg)
+}
+
+// Panicf logs a critical message formatted like fmt.Sprintf and then panics with it.
+// It panics even when CRIT is disabled.
+// Caller tagging is included when enabled in Init.
+func Panicf(format string, v ...any) {
+	msg := sprintf(format, v...)
+	if isLevelEnabled(CritLevel) {
+		logMessage(CritLevel, 2, msg, nil)
+	}
+	panic(msg)
+}
+
+// Panicln logs a critical message formatted like fmt.Sprintln and then panics with it.
+// It panics even when CRIT is disabled.
+// Caller tagging is included when enabled in Init.
+func Panicln(v ...any) {
+	msg := sprintln(v...)
+	if isLevelEnabled(CritLevel) {
+		logMessage(CritLevel, 2, msg, nil)
+	}
+	panic(msg)
+}
+
+// sprintln is fmt.Sprintln without the trailing newline.
+func sprintln(v ...any) string {
+	return strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+}
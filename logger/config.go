@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MarshalText encodes the level as its name, e.g. "WARNING".
+func (l Level) MarshalText() ([]byte, error) {
+	if severity(l) < 0 {
+		return nil, fmt.Errorf("logger: unknown level %d", int(l))
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText decodes a level name using the same case-insensitive names as LOGGER_LEVELS.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, ok := levelFromName(string(text))
+	if !ok {
+		return fmt.Errorf("logger: unknown level %q", text)
+	}
+	*l = level
+	return nil
+}
+
+// String returns the lower-case format name, e.g. "csv".
+func (f Format) String() string {
+	switch f {
+	case FormatText:
+		return "text"
+	case FormatCSV:
+		return "csv"
+	case FormatTSV:
+		return "tsv"
+	case FormatBinary:
+		return "binary"
+	case FormatJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// MarshalText encodes the format as its name.
+func (f Format) MarshalText() ([]byte, error) {
+	if strings.HasPrefix(f.String(), "Format(") {
+		return nil, fmt.Errorf("logger: unknown format %d", int(f))
+	}
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText decodes a case-insensitive format name such as "csv".
+func (f *Format) UnmarshalText(text []byte) error {
+	for _, candidate := range []Format{FormatText, FormatCSV, FormatTSV, FormatBinary, FormatJSON} {
+		if strings.EqualFold(strings.TrimSpace(string(text)), candidate.String()) {
+			*f = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("logger: unknown format %q", text)
+}
+
+// String returns the lower-case style name, e.g. "short".
+func (s LevelPrefixStyle) String() string {
+	switch s {
+	case LevelPrefixFull:
+		return "full"
+	case LevelPrefixShort:
+		return "short"
+	case LevelPrefixNone:
+		return "none"
+	default:
+		return fmt.Sprintf("LevelPrefixStyle(%d)", int(s))
+	}
+}
+
+// MarshalText encodes the style as its name.
+func (s LevelPrefixStyle) MarshalText() ([]byte, error) {
+	if strings.HasPrefix(s.String(), "LevelPrefixStyle(") {
+		return nil, fmt.Errorf("logger: unknown level prefix style %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a case-insensitive style name such as "short".
+func (s *LevelPrefixStyle) UnmarshalText(text []byte) error {
+	for _, candidate := range []LevelPrefixStyle{LevelPrefixFull, LevelPrefixShort, LevelPrefixNone} {
+		if strings.EqualFold(strings.TrimSpace(string(text)), candidate.String()) {
+			*s = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("logger: unknown level prefix style %q", text)
+}
+
+// String returns the lower-case theme name, e.g. "light".
+func (t ColorTheme) String() string {
+	switch t {
+	case ColorThemeDark:
+		return "dark"
+	case ColorThemeLight:
+		return "light"
+	case ColorThemeCustom:
+		return "custom"
+	default:
+		return fmt.Sprintf("ColorTheme(%d)", int(t))
+	}
+}
+
+// MarshalText encodes the theme as its name.
+func (t ColorTheme) MarshalText() ([]byte, error) {
+	if strings.HasPrefix(t.String(), "ColorTheme(") {
+		return nil, fmt.Errorf("logger: unknown color theme %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes a case-insensitive theme name such as "light".
+func (t *ColorTheme) UnmarshalText(text []byte) error {
+	for _, candidate := range []ColorTheme{ColorThemeDark, ColorThemeLight, ColorThemeCustom} {
+		if strings.EqualFold(strings.TrimSpace(string(text)), candidate.String()) {
+			*t = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("logger: unknown color theme %q", text)
+}
+
+// LoadConfig reads a Config from a JSON file, for example:
+//
+//	{"levels": ["INFO", "ERROR"], "file_path": "/var/log/app.log", "format": "csv"}
+//
+// Keys are the snake_case json tags on Config; unknown keys are rejected so
+// typos surface as errors. Level and format names are case-insensitive, and
+// write_timeout is given in nanoseconds. Callback fields cannot be loaded.
+func LoadConfig(path string) (Config, error) {
+	return loadConfigOnto(path, Config{})
+}
+
+// loadConfigOnto is LoadConfig starting from base instead of the zero Config:
+// keys present in the file overwrite base, everything else, including the
+// `json:"-"` callback, writer and pattern fields, is kept.
+func loadConfigOnto(path string, base Config) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("logger: load config: %w", err)
+	}
+	config := base
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("logger: load config %s: %w", path, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,68 @@
+package logger
+
+import "context"
+
+// --- Context-aware structured logging ---
+
+// DebugCtx logs a debug message with structured key-value pairs, followed by
+// trace_id and span_id from ctx when Config.TraceExtractor is set.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func DebugCtx(ctx context.Context, msg string, keyvals ...any) {
+	if !isLevelEnabled(DebugLevel) {
+		return
+	}
+	logMessage(DebugLevel, 2, msg, withTraceIDs(ctx, keyvals))
+}
+
+// InfoCtx logs an informational message with structured key-value pairs, followed by
+// trace_id and span_id from ctx when Config.TraceExtractor is set.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func InfoCtx(ctx context.Context, msg string, keyvals ...any) {
+	if !isLevelEnabled(InfoLevel) {
+		return
+	}
+	logMessage(InfoLevel, 2, msg, withTraceIDs(ctx, keyvals))
+}
+
+// WarnCtx logs a warning message with structured key-value pairs, followed by
+// trace_id and span_id from ctx when Config.TraceExtractor is set.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func WarnCtx(ctx context.Context, msg string, keyvals ...any) {
+	if !isLevelEnabled(WarnLevel) {
+		return
+	}
+	logMessage(WarnLevel, 2, msg, withTraceIDs(ctx, keyvals))
+}
+
+// ErrorCtx logs an error message with structured key-value pairs, followed by
+// trace_id and span_id from ctx when Config.TraceExtractor is set.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func ErrorCtx(ctx context.Context, msg string, keyvals ...any) {
+	if !isLevelEnabled(ErrorLevel) {
+		return
+	}
+	logMessage(ErrorLevel, 2, msg, withTraceIDs(ctx, keyvals))
+}
+
+// withTraceIDs appends the trace_id and span_id reported by the configured
+// TraceExtractor. The extractor runs before the logger lock is taken.
+func withTraceIDs(ctx context.Context, keyvals []any) []any {
+	extract := traceExtractor
+	if extract == nil || ctx == nil {
+		return keyvals
+	}
+	traceID, spanID := extract(ctx)
+	out := make([]any, 0, len(keyvals)+4)
+	out = append(out, keyvals...)
+	if traceID != "" {
+		out = append(out, "trace_id", traceID)
+	}
+	if spanID != "" {
+		out = append(out, "span_id", spanID)
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+)
+
+// csvHeader lists the columns written by FormatCSV and FormatTSV.
+var csvHeader = []string{"timestamp", "level", "caller", "message", "fields"}
+
+// csvSink writes records as CSV (or TSV) rows. Rows are encoded into buf and
+// then written to out, so a failed write loses only its own row instead of
+// leaving csv.Writer's sticky error behind for every later row.
+type csvSink struct {
+	out io.Writer
+	buf bytes.Buffer
+	w   *csv.Writer
+}
+
+// newCSVSink returns a sink writing rows to w separated by comma.
+// Rows end in "\r\n" when crlf is set, otherwise "\n".
+// The header row is written only when writeHeader is set (a new or empty file),
+// so appending to an existing log does not repeat it.
+func newCSVSink(w io.Writer, comma rune, crlf, writeHeader bool) *csvSink {
+	s := &csvSink{out: w}
+	s.w = csv.NewWriter(&s.buf)
+	s.w.Comma = comma
+	s.w.UseCRLF = crlf
+	if writeHeader {
+		_ = s.writeRow(csvHeader)
+	}
+	return s
+}
+
+func (s *csvSink) WriteRecord(rec Record) error {
+	return s.writeRow([]string{
+		rec.Time.Format(time.RFC3339),
+		levelName(rec.Level),
+		rec.Caller,
+		rec.text(),
+		strings.TrimPrefix(encodeFields(rec.Fields), " "),
+	})
+}
+
+// writeRow encodes row and writes it to out in a single call.
+func (s *csvSink) writeRow(row []string) error {
+	s.buf.Reset()
+	if err := s.w.Write(row); err != nil {
+		return err
+	}
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		return err
+	}
+	_, err := s.out.Write(s.buf.Bytes())
+	return err
+}
@@ -0,0 +1,43 @@
+package logger
+
+// initConfig is the Config passed to the last Init.
+var initConfig Config
+
+// CurrentConfig returns the configuration in effect, for introspection such as
+// a /debug/config endpoint. Unlike the Config given to Init it reflects the
+// resolved state: the levels enabled right now (after SetLevels,
+// TemporaryLevels or LOGGER_LEVELS), the current global fields, FilePath only
+// while the log file is open, and defaults filled in for unset fields such as
+// DefaultLevel, StderrThreshold, LineTerminator and FatalExitCode.
+// Slices, maps and funcs other than Levels and GlobalFields are shared with
+// the logger and must not be modified.
+// Thread-safe for concurrent use.
+func CurrentConfig() Config {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	c := initConfig
+	c.Levels = currentLevels()
+	c.GlobalFields = nil
+	for _, f := range globalFields {
+		c.GlobalFields = append(c.GlobalFields, f.Key, f.Value)
+	}
+	if logFile == nil {
+		c.FilePath = ""
+	}
+	level := defaultLevel
+	c.DefaultLevel = &level
+	c.FailFastLevel = failFastLevel
+	c.LineTerminator = lineTerminator
+	c.FatalExitCode = fatalExitCode
+	if c.StderrThreshold == DebugLevel {
+		c.StderrThreshold = WarnLevel
+	}
+	if c.TriggerLevel != DebugLevel && c.TriggerBufferSize <= 0 {
+		c.TriggerBufferSize = defaultTriggerBufferSize
+	}
+	if c.SeverityMarkers && c.SeverityMarkerLevel == DebugLevel {
+		c.SeverityMarkerLevel = CritLevel
+	}
+	return c
+}
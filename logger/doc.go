@@ -13,6 +13,8 @@
 //   - Level filtering via Config.Levels or LOGGER_LEVELS environment variable
 //   - Extended syslog-compatible levels: NOTICE, CRIT, ALERT, EMERG
 //   - Optional file logging with color stripping for files
+//   - CSV/TSV, JSON and length-prefixed binary file output via Config.Format
+//   - Additional record consumers via Config.Sinks
 //   - Journald priority prefixes for plain output when JOURNAL_STREAM is set
 //   - Optional [LEVEL] prefix via Config.IncludeLevelPrefix
 //
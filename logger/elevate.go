@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	elevateMu      sync.Mutex
+	elevateTimer   *time.Timer // nil when no elevation is active
+	elevateGen     uint64      // identifies the call that owns the active elevation
+	elevateRestore []Level
+)
+
+// TemporaryLevels enables levels for d, then restores the levels that were active before.
+// Calling it again while a previous call is still active replaces the levels and
+// restarts the timer; the original levels are still the ones restored.
+// A NOTICE is logged when the levels are raised and again before they are restored.
+//
+// The returned cancel function restores the previous levels immediately.
+// It does nothing once the levels have been restored or a later call has taken over.
+//
+// Example (turn on DEBUG for 30 seconds during an incident):
+//
+//	logger.TemporaryLevels(logger.AllLevels(), 30*time.Second)
+func TemporaryLevels(levels []Level, d time.Duration) (cancel func()) {
+	elevateMu.Lock()
+	if elevateTimer == nil {
+		elevateRestore = currentLevels()
+	} else {
+		elevateTimer.Stop()
+	}
+	SetLevels(levels)
+	elevateGen++
+	gen := elevateGen
+	elevateTimer = time.AfterFunc(d, func() { endTemporaryLevels(gen) })
+	elevateMu.Unlock()
+
+	Noticef("levels temporarily set to %s for %s", levelNames(levels), d)
+	return func() { endTemporaryLevels(gen) }
+}
+
+// endTemporaryLevels restores the saved levels if call gen still owns the elevation.
+func endTemporaryLevels(gen uint64) {
+	elevateMu.Lock()
+	defer elevateMu.Unlock()
+	if elevateTimer == nil || gen != elevateGen {
+		return
+	}
+	elevateTimer.Stop()
+	elevateTimer = nil
+	Noticef("levels restored to %s", levelNames(elevateRestore))
+	SetLevels(elevateRestore)
+}
+
+// currentLevels returns the statically enabled levels in AllLevels order.
+func currentLevels() []Level {
+	mask := levelMask(enabledMask.Load())
+	levels := []Level{}
+	for _, level := range AllLevels() {
+		if mask.has(level) {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// levelNames renders levels as a comma-separated list of levelName, e.g. "DEBUG,INFO".
+func levelNames(levels []Level) string {
+	names := make([]string, 0, len(levels))
+	for _, level := range levels {
+		names = append(names, levelName(level))
+	}
+	return strings.Join(names, ",")
+}
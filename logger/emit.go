@@ -0,0 +1,36 @@
+package logger
+
+// Emit logs a message with structured key-value pairs at level, for call sites
+// that compute the level at runtime (e.g. mapping external severities).
+// It filters and tags the caller exactly like the named KV methods; at
+// FatalLevel it exits with Config.FatalExitCode like FatalKV.
+// Values outside the defined levels are ignored.
+// Thread-safe for concurrent use.
+func Emit(level Level, msg string, keyvals ...any) {
+	if level < 0 || level >= numLevels {
+		return
+	}
+	if isLevelEnabled(level) {
+		logMessage(level, 2, msg, keyvals)
+	}
+	if level == FatalLevel {
+		exitFatal()
+	}
+}
+
+// Emitf logs a message formatted with fmt.Sprintf at level.
+// It filters and tags the caller exactly like the named f methods; at
+// FatalLevel it exits with Config.FatalExitCode like Fatalf.
+// Values outside the defined levels are ignored.
+// Thread-safe for concurrent use.
+func Emitf(level Level, format string, v ...any) {
+	if level < 0 || level >= numLevels {
+		return
+	}
+	if isLevelEnabled(level) {
+		logMessage(level, 2, sprintf(format, v...), nil)
+	}
+	if level == FatalLevel {
+		exitFatal()
+	}
+}
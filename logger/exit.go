@@ -0,0 +1,44 @@
+package logger
+
+import "sync"
+
+var (
+	exitMu       sync.Mutex
+	exitHandlers []func()
+)
+
+// OnExit registers fn to run before the process exits from a Fatal method.
+// Handlers run in registration order after the fatal line has been written,
+// whichever exit code is used; they cannot change the code.
+// Thread-safe for concurrent use.
+func OnExit(fn func()) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitHandlers = append(exitHandlers, fn)
+}
+
+// exit writes records held by Config.TriggerLevel and buffered file output,
+// runs the OnExit handlers and then terminates through exitFunc.
+func exit(code int) {
+	logMutex.Lock()
+	releaseHeldRecords()
+	flushFileBuffer()
+	logMutex.Unlock()
+
+	exitMu.Lock()
+	handlers := append([]func(){}, exitHandlers...)
+	exitMu.Unlock()
+	for _, fn := range handlers {
+		fn()
+	}
+	exitFunc(code)
+}
+
+// exitFatal calls exit with Config.FatalExitCode, read under logMutex so a
+// concurrent Init cannot race with it.
+func exitFatal() {
+	logMutex.Lock()
+	code := fatalExitCode
+	logMutex.Unlock()
+	exit(code)
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	// fileBuffer buffers log file writes when Config.FlushInterval is set;
+	// nil when writes go straight to the file. Guarded by logMutex.
+	fileBuffer *bufio.Writer
+	// fileBufferOut is the writer fileBuffer flushes to. Guarded by logMutex.
+	fileBufferOut io.Writer
+
+	flusherMu   sync.Mutex
+	flusherStop chan struct{} // closed to stop the running flusher; nil when none runs
+	flusherDone chan struct{} // closed once the running flusher has exited
+)
+
+// startFlusher flushes fileBuffer every interval until stopFlusher is called.
+func startFlusher(interval time.Duration) {
+	stopFlusher()
+	flusherMu.Lock()
+	defer flusherMu.Unlock()
+	stop, done := make(chan struct{}), make(chan struct{})
+	flusherStop, flusherDone = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				logMutex.Lock()
+				flushFileBuffer()
+				logMutex.Unlock()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopFlusher stops the background flusher, if any, and waits for it to exit.
+// It must not be called with logMutex held.
+func stopFlusher() {
+	flusherMu.Lock()
+	defer flusherMu.Unlock()
+	if flusherStop == nil {
+		return
+	}
+	close(flusherStop)
+	<-flusherDone
+	flusherStop, flusherDone = nil, nil
+}
+
+// flushFileBuffer writes any buffered file output. Must hold logMutex.
+func flushFileBuffer() {
+	if fileBuffer != nil {
+		reportWriteError(flushBuffered())
+	}
+}
+
+// flushBuffered flushes fileBuffer, discarding what it could not write.
+// Must hold logMutex.
+func flushBuffered() error {
+	if err := fileBuffer.Flush(); err != nil {
+		return discardFileBuffer(err, fileBuffer.Buffered())
+	}
+	return nil
+}
+
+// discardFileBuffer drops the lost bytes left in fileBuffer after a failed
+// write or flush, so bufio's sticky error does not refuse every later line,
+// and returns err with the number of bytes lost. Must hold logMutex.
+func discardFileBuffer(err error, lost int) error {
+	fileBuffer.Reset(fileBufferOut)
+	return fmt.Errorf("%w (%d buffered bytes discarded)", err, lost)
+}
+
+// fileBufferWriter writes to fileBuffer; it is the file sinks' writer when
+// Config.FlushInterval is set. Must hold logMutex.
+type fileBufferWriter struct{}
+
+func (fileBufferWriter) Write(p []byte) (int, error) {
+	n, err := fileBuffer.Write(p)
+	if err != nil {
+		return n, discardFileBuffer(err, fileBuffer.Buffered()+len(p)-n)
+	}
+	return n, nil
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// goroutineID returns the current goroutine's ID parsed from the header of
+// runtime.Stack ("goroutine 123 [running]:"), or 0 if it cannot be parsed.
+// The runtime does not expose goroutine IDs, so this is best effort and only
+// meant for debugging.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// withGoroutineID appends a goid field when Config.IncludeGoroutineID is set.
+func withGoroutineID(fields []Field) []Field {
+	if !includeGoroutineID {
+		return fields
+	}
+	return append(fields, Field{Key: "goid", Value: goroutineID()})
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// writeHeader writes the Config.Header block to a new or empty text log file:
+//
+//	# ---- log started 2024-03-09T14:05:06Z ----
+//	# host=web-1 levels=INFO,WARNING,ERROR version=1.4.2
+//	# ----
+//
+// Lines start with "#" so they are easy to skip when parsing the file.
+func writeHeader(w io.Writer, keyvals []any) error {
+	fields := []Field{{Key: "levels", Value: levelNames(currentLevels())}}
+	if host, err := os.Hostname(); err == nil {
+		fields = append([]Field{{Key: "host", Value: host}}, fields...)
+	}
+	fields = append(fields, collectFields(keyvals)...)
+
+	var b strings.Builder
+	b.WriteString("# ---- log started " + nowFunc().Format(time.RFC3339) + " ----" + lineTerminator)
+	b.WriteString("#" + encodeFields(fields) + lineTerminator)
+	b.WriteString("# ----" + lineTerminator)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -1,13 +1,22 @@
 package logger
 
 import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
+	"reflect"
+	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -40,19 +49,346 @@ const (
 type Config struct {
 	// Levels limits which log levels are enabled; nil falls back to LOGGER_LEVELS or all levels.
 	// Default: nil (all levels enabled)
-	Levels []Level
+	Levels []Level `json:"levels"`
 	// Colorize enables ANSI color output for console logs.
 	// Default: false
-	Colorize bool
+	Colorize bool `json:"colorize"`
 	// FilePath writes logs to this file (created/appended); empty disables file logging.
 	// Default: "" (file logging disabled)
-	FilePath string
+	FilePath string `json:"file_path"`
 	// IncludeLevelPrefix adds the [LEVEL] tag in console and file output.
 	// Default: false
-	IncludeLevelPrefix bool
+	IncludeLevelPrefix bool `json:"include_level_prefix"`
+	// LowercaseLevels renders level names in lower case ("[info]", "level":"error")
+	// in prefixes, JSON and CSV files and header lines. Parsing (LOGGER_LEVELS,
+	// LoadConfig) stays case-insensitive.
+	// Default: false (upper case)
+	LowercaseLevels bool `json:"lowercase_levels"`
+	// PadLevelPrefix right-pads the full level prefix to the longest name, so
+	// "[INFO]   " and "[WARNING]" start messages in the same column.
+	// Default: false
+	PadLevelPrefix bool `json:"pad_level_prefix"`
+	// LevelPrefixStyle selects how IncludeLevelPrefix renders the level:
+	// "[WARNING]", "W" or nothing. See LevelPrefixStyle for the short letters.
+	// Default: LevelPrefixFull
+	LevelPrefixStyle LevelPrefixStyle `json:"level_prefix_style"`
 	// IncludeCallerTag adds the [package.Function:line] tag in log messages.
 	// Default: false
-	IncludeCallerTag bool
+	IncludeCallerTag bool `json:"include_caller_tag"`
+	// GlobalFields are key-value pairs appended to every line from every logging method.
+	// Default: nil
+	GlobalFields []any `json:"global_fields"`
+	// AllowMultiline keeps line breaks in text field values, wrapping such a value
+	// in a block so it stays contiguous and recognizable:
+	//	stack=<<<
+	//	line 1
+	//	line 2
+	//	>>>
+	// By default line breaks are escaped as \n and \r so each record is one line.
+	// JSON and binary files always keep values intact.
+	// Default: false
+	AllowMultiline bool `json:"allow_multiline"`
+	// MaxFields caps the key-value pairs per line, including global fields; extra
+	// pairs are replaced by a single "…(+M more)" marker. Zero means unlimited.
+	// Default: 0
+	MaxFields int `json:"max_fields"`
+	// IncludePID appends a pid=<process id> field to every line.
+	// Default: false
+	IncludePID bool `json:"include_pid"`
+	// IncludeHostname appends a host=<hostname> field to every line; the hostname
+	// is resolved once at Init and omitted if it cannot be determined.
+	// Default: false
+	IncludeHostname bool `json:"include_hostname"`
+	// IncludeGoroutineID appends a goid=<goroutine id> field to every line, for
+	// debugging concurrency. The ID is parsed from runtime.Stack, which costs a
+	// few hundred nanoseconds per line.
+	// Default: false
+	IncludeGoroutineID bool `json:"include_goroutine_id"`
+	// IncludeSequence appends a seq=N field to every line on every output,
+	// numbered by a process-wide counter. Only lines that pass level filtering
+	// (and are released by TriggerLevel) take a number, so a gap in the
+	// sequence means a line was lost on the way, not suppressed.
+	// Default: false
+	IncludeSequence bool `json:"include_sequence"`
+	// DedupeFields keeps only the last value for a repeated key, at the key's first position.
+	// Default: false (duplicates are kept)
+	DedupeFields bool `json:"dedupe_fields"`
+	// SortFields orders key-value pairs alphabetically by key.
+	// Default: false (call order)
+	SortFields bool `json:"sort_fields"`
+	// DefaultLevel is the level used by Print, Printf and Println. It is a
+	// pointer so DebugLevel, the zero Level, can be chosen; nil keeps the default.
+	// Default: nil (InfoLevel)
+	DefaultLevel *Level `json:"default_level"`
+	// TriggerLevel holds lines below this level in memory instead of writing them
+	// ("quiet until error"). A line at or above it first writes the held lines,
+	// oldest first, then itself. Held lines are discarded by Close and Init, but
+	// written before a Fatal or FailFastLevel exit, and a line that ends the
+	// process is never held. The zero value (DebugLevel) disables holding.
+	// Default: off
+	TriggerLevel Level `json:"trigger_level"`
+	// TriggerBufferSize is how many held lines TriggerLevel keeps; older lines are dropped.
+	// Default: 256
+	TriggerBufferSize int `json:"trigger_buffer_size"`
+	// SummaryOnClose makes Close log a NOTICE line with the number of lines
+	// written per level since Init, e.g. "log summary info=340 warning=4 error=1",
+	// through every output including the file. Lines held by TriggerLevel and
+	// never written are not counted, and the summary itself is not held.
+	// Default: false
+	SummaryOnClose bool `json:"summary_on_close"`
+	// FailFastLevel exits the program through the Fatal exit path (FatalExitCode and
+	// OnExit handlers) after writing any line at or above this level, e.g. ErrorLevel in CI.
+	// The zero value (DebugLevel) disables fail-fast.
+	// Default: off
+	FailFastLevel Level `json:"fail_fast_level"`
+	// StderrThreshold is the lowest level written to stderr; less severe levels go to stdout.
+	// The zero value (DebugLevel) keeps the default.
+	// Default: WarnLevel (DEBUG/INFO/NOTICE to stdout, WARNING and above to stderr)
+	StderrThreshold Level `json:"stderr_threshold"`
+	// SingleThreaded skips the logger lock on the logging path, saving the mutex
+	// overhead in CLI tools that log from one goroutine only.
+	// WARNING: with this set, logging from more than one goroutine at a time is a
+	// data race and can corrupt output. Background goroutines that log or write
+	// outputs (FlushInterval, TemporaryLevels, WatchConfig, signal handlers) count
+	// as concurrent writers, so do not combine them with SingleThreaded.
+	// Default: false (locked, safe for concurrent use)
+	SingleThreaded bool `json:"single_threaded"`
+	// Routing overrides the console destination per level: stdout, stderr, the
+	// log file only, nowhere, or a custom writer (see RouteSpec). It is the most
+	// general routing knob; levels without an entry follow SingleStream and
+	// StderrThreshold. Colors, level prefixes and journald prefixes still apply.
+	// Default: nil
+	Routing map[Level]RouteSpec `json:"-"`
+	// SingleStream sends every level to stdout, for platforms that treat any
+	// stderr output as an error (common on Kubernetes). StderrThreshold is ignored.
+	// Default: false
+	SingleStream bool `json:"single_stream"`
+	// LevelMirrors copies each level's lines to an extra writer, in the same plain
+	// timestamped text as a FormatText file. A failing mirror does not affect other outputs.
+	// Example: map[Level]io.Writer{ErrorLevel: errFile, CritLevel: errFile}
+	// Default: nil
+	LevelMirrors map[Level]io.Writer `json:"-"`
+	// StrictFormat emits a WARNING (with the caller tag) whenever a formatted
+	// call produces a fmt error such as "%!d(string=x)" or "%!(EXTRA ...)".
+	// A "%!" inside argument text does not count. The best-effort message is
+	// still logged.
+	// Default: false
+	StrictFormat bool `json:"strict_format"`
+	// BytesAsHex renders []byte field values as hex instead of text.
+	// Default: false
+	BytesAsHex bool `json:"bytes_as_hex"`
+	// Format selects how records are written to FilePath; console output is always text.
+	// Default: FormatText
+	Format Format `json:"format"`
+	// WriteTimeout bounds how long a single write to any output may block; a write that
+	// times out is skipped, counted by DroppedLines and reported to OnWriteError.
+	// Default: 0 (writes may block indefinitely)
+	WriteTimeout time.Duration `json:"write_timeout"`
+	// Header writes a "#"-prefixed block with the start time, hostname, enabled
+	// levels and these key-value pairs (e.g. "version", "1.4.2") when Init opens a
+	// new or empty text log file; appending to a non-empty file writes nothing.
+	// Only used with FormatText.
+	// Default: nil (no header)
+	Header []any `json:"header"`
+	// SeverityMarkers wraps text log file lines at or above SeverityMarkerLevel
+	// in plain-text markers, ">>> CRIT <line> <<<", so rare critical events are
+	// easy to find and grep for in a file without colors. The console, mirrors
+	// and other formats are unaffected.
+	// Default: false
+	SeverityMarkers bool `json:"severity_markers"`
+	// SeverityMarkerLevel is the lowest severity marked by SeverityMarkers.
+	// Default: CritLevel
+	SeverityMarkerLevel Level `json:"severity_marker_level"`
+	// FlushInterval buffers log file writes in memory and flushes them when the
+	// buffer fills and every interval, trading durability for fewer writes. Flush,
+	// Close and the Fatal methods flush pending bytes; a crash can lose up to one interval.
+	// A failed flush discards the pending bytes, reports how many to OnWriteError
+	// and keeps buffering later lines.
+	// Default: 0 (every line is written to the file immediately)
+	FlushInterval time.Duration `json:"flush_interval"`
+	// OnWriteError is called with errors from any output, including ErrWriteTimeout.
+	// It runs while the logger lock is held and must not call logging functions.
+	// Default: nil (errors are ignored)
+	OnWriteError func(err error) `json:"-"`
+	// FatalExitCode is the process exit code used by Fatalf, Fatalln and FatalKV.
+	// The zero value keeps the default.
+	// Default: 1
+	FatalExitCode int `json:"fatal_exit_code"`
+	// CallerSkipPackages lists function name prefixes (e.g. "github.com/gin-gonic/") whose frames
+	// are skipped when resolving the caller tag, so it points at the first application frame.
+	// Default: nil
+	CallerSkipPackages []string `json:"caller_skip_packages"`
+	// RedactPatterns masks every match in messages and rendered field values with
+	// "***" before any output sees the line. Capturing groups are kept, so
+	// `\b\d{12}(\d{4})\b` turns a card number into "***1111". Audit lines stay
+	// verbatim. Patterns only run when set. They cannot be loaded from JSON, so
+	// WatchConfig keeps the patterns of the last Init when it reinitializes.
+	// Default: nil
+	RedactPatterns []*regexp.Regexp `json:"-"`
+	// FileFieldDenylist lists field keys dropped from the log file (any Format)
+	// while still shown on the console, mirrors and Sinks.
+	// Default: nil
+	FileFieldDenylist []string `json:"file_field_denylist"`
+	// Sinks receive every logged record in addition to the console and file outputs,
+	// e.g. a journald or network collector. Each sink renders the record itself and
+	// may filter on Record.Level. Sinks are called while the logger lock is held, in
+	// order, and must not call logging functions or modify Record.Fields.
+	// Default: nil
+	Sinks []Sink `json:"-"`
+	// SinkFunc receives every record that passes level filtering, after Sinks,
+	// so callers can forward logs over their own transport (Kafka, CloudWatch, HTTP).
+	// It runs under the logger lock like Sinks: a slow transport should hand records
+	// to its own goroutine or queue. It may keep rec but must not modify rec.Fields.
+	// A panic in SinkFunc is recovered and reported through OnWriteError.
+	// Default: nil
+	SinkFunc func(rec Record) `json:"-"`
+	// AuditFilePath receives the lines written by Audit, separate from FilePath.
+	// Default: "" (audit lines go to stderr)
+	AuditFilePath string `json:"audit_file_path"`
+	// AuditSequence numbers audit lines (seq=1, 2, ...) so gaps reveal removed lines.
+	// Default: false
+	AuditSequence bool `json:"audit_sequence"`
+	// TraceExtractor returns the trace and span IDs carried by a context, e.g. from
+	// OpenTelemetry's trace.SpanContextFromContext. The *Ctx methods append them as
+	// trace_id and span_id fields; empty IDs are omitted.
+	// Default: nil (no extraction)
+	TraceExtractor func(ctx context.Context) (traceID, spanID string) `json:"-"`
+	// LineTerminator ends every console, text file, JSON and audit line, e.g. "\r\n" for
+	// Windows consumers or "\x1e" for record-separated collectors. CSV/TSV rows use
+	// "\r\n" when it is "\r\n" and "\n" otherwise; binary frames are unaffected.
+	// The empty string keeps the default.
+	// Default: "\n"
+	LineTerminator string `json:"line_terminator"`
+	// FieldPrefix separates the message from the field block on console, text
+	// file and audit lines, e.g. " | " for "msg | key=value". Lines without
+	// fields are unaffected, and lines with an empty message start directly
+	// with the fields. The empty string keeps the default.
+	// Default: " "
+	FieldPrefix string `json:"field_prefix"`
+	// DualTimeZone adds the time in this location after the console timestamp,
+	// e.g. "2024/03/09 14:05:06 (13:05:06 UTC)". Console timestamps are shown when
+	// Colorize is set; files are unaffected.
+	// Default: nil
+	DualTimeZone *time.Location `json:"-"`
+	// ColorTheme picks the level colors of a colorized console: ColorThemeDark,
+	// ColorThemeLight (darker colors for light backgrounds) or ColorThemeCustom.
+	// Default: ColorThemeDark
+	ColorTheme ColorTheme `json:"color_theme"`
+	// LevelColors sets ANSI color sequences per level, e.g. "\033[31m", for
+	// ColorThemeCustom; levels without an entry keep the dark theme color.
+	// Default: nil
+	LevelColors map[Level]string `json:"level_colors"`
+	// ColorFields dims the keys of key=value pairs in console output; files and
+	// mirrors stay plain. Only applied when Colorize is set.
+	// Default: false
+	ColorFields bool `json:"color_fields"`
+	// Highlights colors substrings of console lines matching each rule; only applied when Colorize is set.
+	// Default: nil (no highlighting)
+	Highlights []HighlightRule `json:"highlights"`
+}
+
+// Format selects the encoding used for the log file.
+type Format int
+
+const (
+	// FormatText writes the console line prefixed with a timestamp.
+	FormatText Format = iota
+	// FormatCSV writes one comma-separated row per record with the columns
+	// timestamp, level, caller, message, fields. All key-value pairs share the
+	// single fields column as "key=value" pairs, so every row has the same width.
+	// A header row is written when the file is new or empty.
+	FormatCSV
+	// FormatTSV is FormatCSV with tab-separated columns.
+	FormatTSV
+	// FormatBinary writes length-prefixed binary frames for collectors; see
+	// DecodeFrame for the wire format.
+	FormatBinary
+	// FormatJSON writes one JSON object per line with the keys time, level,
+	// caller (when set) and msg, followed by the record's fields.
+	FormatJSON
+)
+
+// LevelPrefixStyle selects how the level prefix is rendered when
+// Config.IncludeLevelPrefix is set.
+type LevelPrefixStyle int
+
+const (
+	// LevelPrefixFull renders the bracketed level name, e.g. "[WARNING]".
+	LevelPrefixFull LevelPrefixStyle = iota
+	// LevelPrefixShort renders a single letter: D (DEBUG), I (INFO), N (NOTICE),
+	// W (WARNING), E (ERROR), C (CRIT), A (ALERT), M (EMERG), F (FATAL).
+	LevelPrefixShort
+	// LevelPrefixNone renders no level prefix.
+	LevelPrefixNone
+)
+
+// levelTag renders the prefix for the level name in style; empty means no prefix.
+// The tag is lower-cased with Config.LowercaseLevels and, in the full style,
+// right-padded to the width of "[WARNING]" with Config.PadLevelPrefix.
+func levelTag(level string, style LevelPrefixStyle) string {
+	var tag string
+	switch style {
+	case LevelPrefixFull:
+		tag = "[" + level + "]"
+		if padLevelPrefix {
+			tag += strings.Repeat(" ", len("[WARNING]")-len(tag))
+		}
+	case LevelPrefixShort:
+		if level == "EMERG" {
+			tag = "M"
+		} else {
+			tag = level[:1]
+		}
+	default:
+		return ""
+	}
+	if lowercaseLevels {
+		return strings.ToLower(tag)
+	}
+	return tag
+}
+
+// levelName returns the level name written to output, e.g. in the JSON
+// "level" field: Level.String, lower-cased with Config.LowercaseLevels.
+func levelName(level Level) string {
+	if lowercaseLevels {
+		return strings.ToLower(level.String())
+	}
+	return level.String()
+}
+
+// HighlightRule colors every match of Pattern in console output with Color,
+// an ANSI escape sequence such as "\033[31m".
+// Rules apply in order; where matches overlap, the earlier rule wins.
+type HighlightRule struct {
+	Pattern *regexp.Regexp `json:"pattern"`
+	Color   string         `json:"color"`
+}
+
+// String returns the upper-case level name used in prefixes, e.g. "WARNING".
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case NoticeLevel:
+		return "NOTICE"
+	case WarnLevel:
+		return "WARNING"
+	case ErrorLevel:
+		return "ERROR"
+	case CritLevel:
+		return "CRIT"
+	case AlertLevel:
+		return "ALERT"
+	case EmergLevel:
+		return "EMERG"
+	case FatalLevel:
+		return "FATAL"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
 }
 
 // AllLevels returns all supported levels.
@@ -70,6 +406,38 @@ func AllLevels() []Level {
 	}
 }
 
+// severity returns the rank of a level in ascending order of importance,
+// following the ordering of AllLevels (DEBUG lowest, FATAL highest).
+func severity(level Level) int {
+	for i, l := range AllLevels() {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// slogLevel maps a level onto the slog.Level scale.
+// DEBUG, INFO, WARNING and ERROR match their slog counterparts; the extended
+// levels are placed between or above them in steps that preserve the severity ordering.
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case NoticeLevel:
+		return slog.LevelInfo + 2
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	default:
+		// CRIT=12, ALERT=16, EMERG=20, FATAL=24
+		return slog.LevelError + slog.Level(4*(severity(level)-severity(ErrorLevel)))
+	}
+}
+
 // global state
 var (
 	// log.Logger instances for formatted output
@@ -95,72 +463,364 @@ var (
 	// Mutex for thread-safe logging across concurrent goroutines
 	logMutex sync.Mutex
 
-	// enabled levels (for filtering)
-	enabledLevels = allLevelsEnabled()
+	// initialized is set by the first Init; preInitWarned once the warning
+	// about logging before Init has been written.
+	initialized   atomic.Bool
+	preInitWarned atomic.Bool
+
+	// enabledMask holds the enabled levels (for filtering) as a levelMask, so
+	// checks are a lock-free load. All levels are enabled until Init.
+	enabledMask atomic.Uint32
+
+	// muted is set by Disable; it silences every level without touching
+	// enabledMask or the leveler.
+	muted atomic.Bool
 
 	// logFile holds the file handle for file logging (if enabled)
 	logFile *os.File
 
+	// logFilePath is the Config.FilePath passed to the last Init.
+	logFilePath string
+
+	// onWriteError receives output errors; see Config.OnWriteError.
+	onWriteError func(err error)
+
+	// droppedLines counts writes skipped because an output timed out.
+	droppedLines atomic.Uint64
+
+	// fileSink renders records for the log file in Config.Format; nil when file logging is disabled.
+	fileSink Sink
+
+	// mirrorSinks render Config.LevelMirrors; Close leaves them open.
+	mirrorSinks map[Level]*textSink
+
+	// fileFieldDenylist holds the keys of Config.FileFieldDenylist.
+	fileFieldDenylist map[string]bool
+
+	// sinks holds Config.Sinks.
+	sinks []Sink
+
+	// traceExtractor holds Config.TraceExtractor.
+	traceExtractor func(ctx context.Context) (traceID, spanID string)
+
 	// includeCallerTag controls whether caller info is added to log messages.
 	includeCallerTag = false
+
+	// colorLoggers are the colorized level loggers built by Init; writeRecord
+	// prepends the record time to their lines after the level prefix and, with
+	// colorFields, colors the field keys.
+	colorLoggers map[*log.Logger]bool
+
+	// defaultLevel is the level of Print, Printf and Println; see Config.DefaultLevel.
+	defaultLevel = InfoLevel
+
+	// lineTerminator ends each rendered line; see Config.LineTerminator.
+	lineTerminator = "\n"
+
+	// fieldPrefix precedes the field block; see Config.FieldPrefix.
+	fieldPrefix = " "
+
+	// fileSkip holds the levels routed to RouteDiscard, which skip the log file.
+	fileSkip levelMask
+
+	// textPrefixStyle is the level prefix style of plain text outputs,
+	// LevelPrefixNone unless Config.IncludeLevelPrefix is set.
+	textPrefixStyle = LevelPrefixNone
+
+	// summaryOnClose holds Config.SummaryOnClose.
+	summaryOnClose bool
+
+	// singleThreaded holds Config.SingleThreaded. It is read before taking
+	// logMutex, so Init may change it while other goroutines log.
+	singleThreaded atomic.Bool
+
+	// allowMultiline holds Config.AllowMultiline.
+	allowMultiline bool
+
+	// padLevelPrefix holds Config.PadLevelPrefix.
+	padLevelPrefix bool
+
+	// lowercaseLevels holds Config.LowercaseLevels.
+	lowercaseLevels bool
+
+	// dualTimeZone holds Config.DualTimeZone.
+	dualTimeZone *time.Location
+
+	// colorFields holds Config.ColorFields.
+	colorFields bool
+
+	// strictFormat holds Config.StrictFormat. It is read before taking
+	// logMutex, like singleThreaded.
+	strictFormat atomic.Bool
+
+	// bytesAsHex holds Config.BytesAsHex.
+	bytesAsHex bool
+
+	// globalFields are appended to every record; guarded by logMutex.
+	globalFields []Field
+
+	// processFields holds the pid/host fields from Config.IncludePID and
+	// Config.IncludeHostname; unlike globalFields, SetGlobalFields leaves them alone.
+	processFields []Field
+
+	// dedupeFields and sortFields normalize structured fields; see Config.
+	dedupeFields bool
+	sortFields   bool
+
+	// includeGoroutineID holds Config.IncludeGoroutineID.
+	includeGoroutineID bool
+
+	// maxFields holds Config.MaxFields.
+	maxFields int
+
+	// failFastLevel holds Config.FailFastLevel; DebugLevel means off.
+	failFastLevel = DebugLevel
+
+	// fatalExitCode is the exit code used by Fatalf, Fatalln and FatalKV.
+	fatalExitCode = 1
+
+	// callerSkipPrefixes holds Config.CallerSkipPackages.
+	callerSkipPrefixes []string
+
+	// highlightRules are applied to colorized console output.
+	highlightRules []HighlightRule
+
+	// leveler, when set, replaces enabledMask as the source of level filtering.
+	leveler atomic.Pointer[levelerHolder]
 )
 
+// levelerHolder boxes a slog.Leveler so it can be stored atomically.
+type levelerHolder struct {
+	l slog.Leveler
+}
+
 // Dependency injection points for testing outputs.
 var (
 	outStdout io.Writer = os.Stdout
 	outStderr io.Writer = os.Stderr
+	exitFunc            = os.Exit
+	// nowFunc is the clock behind every timestamp: record times, file
+	// timestamps, colorized console timestamps and span durations.
+	nowFunc = time.Now
 )
 
 // Init initializes the logger with configurable levels and optional color output.
 // If Config.Levels is nil, LOGGER_LEVELS is used when set; otherwise all levels are enabled.
+// Until Init is called the loggers discard their output; the first line logged
+// before Init prints a one-time "Init not called" warning to stderr.
 //
-// Output routing:
+// Output routing (default, see Config.StderrThreshold):
 //   - DEBUG, INFO, NOTICE are written to stdout
 //   - WARNING, ERROR, CRIT, ALERT, EMERG, FATAL are written to stderr
+//   - with Config.SingleStream every level is written to stdout
 //
 // If Config.FilePath is set but the file cannot be opened, an error is written to stderr
 // and logging continues to console only (non-fatal).
 //
+// A standard stream that is already closed (common for daemons started without a
+// terminal) is replaced with io.Discard, and a NOTICE about the fallback is logged
+// so it still reaches the log file and any sinks.
+//
 // Call Close() to properly close the log file when shutting down.
+//
+// Init swaps the configuration in under the logger lock, so it may be called
+// while other goroutines log; each line uses either the old or the new setup.
 func Init(config Config) {
-	enabledLevels = resolveLevels(config.Levels)
-	showLevel := config.IncludeLevelPrefix
+	stopFlusher()
+	var stdoutErr, stderrErr error
+	startFlush := false
+	defer func() {
+		// Runs after the unlock: the flusher and the notices take logMutex.
+		if startFlush {
+			startFlusher(config.FlushInterval)
+		}
+		noticeStreamFallback("stdout", stdoutErr)
+		noticeStreamFallback("stderr", stderrErr)
+	}()
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	initialized.Store(true)
+	initConfig = config
+	SetLevels(config.Levels)
+	prefixStyle := config.LevelPrefixStyle
+	if !config.IncludeLevelPrefix {
+		prefixStyle = LevelPrefixNone
+	}
+	textPrefixStyle = prefixStyle
+	lowercaseLevels = config.LowercaseLevels
+	padLevelPrefix = config.PadLevelPrefix
+	allowMultiline = config.AllowMultiline
+	singleThreaded.Store(config.SingleThreaded)
+	summaryOnClose = config.SummaryOnClose
+	resetCounts()
 	includeCallerTag = config.IncludeCallerTag
+	highlightRules = config.Highlights
+	colorFields = config.ColorFields
+	dualTimeZone = config.DualTimeZone
+	defaultLevel = InfoLevel
+	if config.DefaultLevel != nil {
+		defaultLevel = *config.DefaultLevel
+	}
+	lineTerminator = config.LineTerminator
+	if lineTerminator == "" {
+		lineTerminator = "\n"
+	}
+	fieldPrefix = config.FieldPrefix
+	if fieldPrefix == "" {
+		fieldPrefix = " "
+	}
+	callerSkipPrefixes = config.CallerSkipPackages
+	failFastLevel = config.FailFastLevel
+	fatalExitCode = 1
+	if config.FatalExitCode != 0 {
+		fatalExitCode = config.FatalExitCode
+	}
+	dedupeFields = config.DedupeFields
+	bytesAsHex = config.BytesAsHex
+	strictFormat.Store(config.StrictFormat)
+	globalFields = collectFields(config.GlobalFields)
+	processFields = resolveProcessFields(config.IncludePID, config.IncludeHostname)
+	includeGoroutineID = config.IncludeGoroutineID
+	includeSequence = config.IncludeSequence
+	sortFields = config.SortFields
+	maxFields = config.MaxFields
+	onWriteError = config.OnWriteError
+	sinks = config.Sinks
+	if config.SinkFunc != nil {
+		sinks = append(sinks[:len(sinks):len(sinks)], funcSink(config.SinkFunc))
+	}
+	traceExtractor = config.TraceExtractor
+	redactRules = newRedactRules(config.RedactPatterns)
+
+	var stdout, stderr io.Writer
+	stdout, stdoutErr = usableStream(outStdout)
+	stderr, stderrErr = usableStream(outStderr)
+	stdout = withWriteTimeout(stdout, config.WriteTimeout)
+	stderr = withWriteTimeout(stderr, config.WriteTimeout)
+	threshold := config.StderrThreshold
+	if threshold == DebugLevel {
+		threshold = WarnLevel
+	}
+	fileSkip = 0
+	streamFor := func(level Level) io.Writer {
+		if route, ok := config.Routing[level]; ok {
+			if route.Dest == RouteDiscard {
+				fileSkip |= 1 << level
+			}
+			return routeOutput(route, stdout, stderr, config.WriteTimeout)
+		}
+		if !config.SingleStream && severity(level) >= severity(threshold) {
+			return stderr
+		}
+		return stdout
+	}
+
+	openAuditFile(config)
 
 	// Open log file if specified
-	var fileWriter io.Writer
+	fileSink = nil
+	fileBuffer, fileBufferOut = nil, nil
+	triggerLevel = config.TriggerLevel
+	size := config.TriggerBufferSize
+	if size <= 0 {
+		size = defaultTriggerBufferSize
+	}
+	heldRecords = recordRing{}
+	if triggerLevel != DebugLevel {
+		heldRecords = newRecordRing(size)
+	}
+	logFilePath = config.FilePath
 	if config.FilePath != "" {
 		f, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 		if err != nil {
 			fmt.Fprintf(outStderr, "failed to open log file %s: %v\n", config.FilePath, err)
 		} else {
 			logFile = f
-			fileWriter = f
+			out := withWriteTimeout(f, config.WriteTimeout)
+			if config.FlushInterval > 0 {
+				fileBuffer, fileBufferOut = bufio.NewWriter(out), out
+				out = fileBufferWriter{}
+				startFlush = true
+			}
+			switch config.Format {
+			case FormatCSV:
+				fileSink = newCSVSink(out, ',', lineTerminator == "\r\n", isEmptyFile(f))
+			case FormatTSV:
+				fileSink = newCSVSink(out, '\t', lineTerminator == "\r\n", isEmptyFile(f))
+			case FormatBinary:
+				fileSink = &binarySink{w: out}
+			case FormatJSON:
+				fileSink = &jsonSink{w: out, terminator: lineTerminator}
+			default:
+				if config.Header != nil && isEmptyFile(f) {
+					reportWriteError(writeHeader(out, config.Header))
+				}
+				sink := &textSink{w: out, style: prefixStyle}
+				if config.SeverityMarkers {
+					sink.markLevel = config.SeverityMarkerLevel
+					if sink.markLevel == DebugLevel {
+						sink.markLevel = CritLevel
+					}
+				}
+				fileSink = sink
+			}
 		}
 	}
+	fileFieldDenylist = keySet(config.FileFieldDenylist)
+
+	mirrorSinks = nil
+	for level, mirror := range config.LevelMirrors {
+		if mirror == nil {
+			continue
+		}
+		if mirrorSinks == nil {
+			mirrorSinks = make(map[Level]*textSink, len(config.LevelMirrors))
+		}
+		mirrorSinks[level] = &textSink{w: withWriteTimeout(mirror, config.WriteTimeout), style: prefixStyle}
+	}
 
 	if config.Colorize {
-		Debug = newColorLogger(outStdout, "DEBUG", showLevel, fileWriter)
-		Info = newColorLogger(outStdout, "INFO", showLevel, fileWriter)
-		Notice = newColorLogger(outStdout, "NOTICE", showLevel, fileWriter)
-		Warning = newColorLogger(outStderr, "WARNING", showLevel, fileWriter)
-		Error = newColorLogger(outStderr, "ERROR", showLevel, fileWriter)
-		Crit = newColorLogger(outStderr, "CRIT", showLevel, fileWriter)
-		Alert = newColorLogger(outStderr, "ALERT", showLevel, fileWriter)
-		Emerg = newColorLogger(outStderr, "EMERG", showLevel, fileWriter)
-		Fatal = newColorLogger(outStderr, "FATAL", showLevel, fileWriter)
+		levelColors = paletteFor(config.ColorTheme, config.LevelColors)
+		Debug = newColorLogger(streamFor(DebugLevel), "DEBUG", prefixStyle)
+		Info = newColorLogger(streamFor(InfoLevel), "INFO", prefixStyle)
+		Notice = newColorLogger(streamFor(NoticeLevel), "NOTICE", prefixStyle)
+		Warning = newColorLogger(streamFor(WarnLevel), "WARNING", prefixStyle)
+		Error = newColorLogger(streamFor(ErrorLevel), "ERROR", prefixStyle)
+		Crit = newColorLogger(streamFor(CritLevel), "CRIT", prefixStyle)
+		Alert = newColorLogger(streamFor(AlertLevel), "ALERT", prefixStyle)
+		Emerg = newColorLogger(streamFor(EmergLevel), "EMERG", prefixStyle)
+		Fatal = newColorLogger(streamFor(FatalLevel), "FATAL", prefixStyle)
+		colorLoggers = map[*log.Logger]bool{
+			Debug: true, Info: true, Notice: true, Warning: true, Error: true,
+			Crit: true, Alert: true, Emerg: true, Fatal: true,
+		}
 		return
 	}
 
-	Debug = newPlainLogger(outStdout, "DEBUG", showLevel, fileWriter)
-	Info = newPlainLogger(outStdout, "INFO", showLevel, fileWriter)
-	Notice = newPlainLogger(outStdout, "NOTICE", showLevel, fileWriter)
-	Warning = newPlainLogger(outStderr, "WARNING", showLevel, fileWriter)
-	Error = newPlainLogger(outStderr, "ERROR", showLevel, fileWriter)
-	Crit = newPlainLogger(outStderr, "CRIT", showLevel, fileWriter)
-	Alert = newPlainLogger(outStderr, "ALERT", showLevel, fileWriter)
-	Emerg = newPlainLogger(outStderr, "EMERG", showLevel, fileWriter)
-	Fatal = newPlainLogger(outStderr, "FATAL", showLevel, fileWriter)
+	colorLoggers = nil
+
+	Debug = newPlainLogger(streamFor(DebugLevel), "DEBUG", prefixStyle)
+	Info = newPlainLogger(streamFor(InfoLevel), "INFO", prefixStyle)
+	Notice = newPlainLogger(streamFor(NoticeLevel), "NOTICE", prefixStyle)
+	Warning = newPlainLogger(streamFor(WarnLevel), "WARNING", prefixStyle)
+	Error = newPlainLogger(streamFor(ErrorLevel), "ERROR", prefixStyle)
+	Crit = newPlainLogger(streamFor(CritLevel), "CRIT", prefixStyle)
+	Alert = newPlainLogger(streamFor(AlertLevel), "ALERT", prefixStyle)
+	Emerg = newPlainLogger(streamFor(EmergLevel), "EMERG", prefixStyle)
+	Fatal = newPlainLogger(streamFor(FatalLevel), "FATAL", prefixStyle)
+}
+
+// isEmptyFile reports whether f currently has no content.
+func isEmptyFile(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Size() == 0
+}
+
+// DroppedLines returns how many writes have been skipped since the process
+// started because an output did not finish within Config.WriteTimeout.
+func DroppedLines() uint64 {
+	return droppedLines.Load()
 }
 
 // InitWithFile initializes the logger with a file path override.
@@ -169,13 +829,43 @@ func InitWithFile(config Config, filePath string) {
 	Init(config)
 }
 
-// Close closes the log file if it was opened.
+// Close closes the log file and the audit file if they were opened.
 // Call this function when your application shuts down to ensure logs are flushed.
+// With Config.SummaryOnClose it first logs the per-level line counts at NOTICE.
 func Close() error {
+	if summaryOnClose {
+		logSummary()
+	}
+	stopFlusher()
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	heldRecords.reset()
+	flushFileBuffer()
+	fileBuffer, fileBufferOut = nil, nil
+	fileSink = nil
+	err := closeAuditFile()
 	if logFile != nil {
-		err := logFile.Close()
+		err = errors.Join(logFile.Close(), err)
 		logFile = nil
-		return err
+	}
+	return err
+}
+
+// Flush writes lines buffered by Config.FlushInterval and commits the log file
+// contents to stable storage.
+// It is a no-op when file logging is disabled.
+func Flush() error {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	if fileBuffer != nil {
+		if err := flushBuffered(); err != nil {
+			return err
+		}
+	}
+	if logFile != nil {
+		return logFile.Sync()
 	}
 	return nil
 }
@@ -238,238 +928,1083 @@ func parseLevels(s string) map[Level]bool {
 		return m
 	}
 	for _, p := range strings.Split(s, ",") {
-		switch strings.ToUpper(strings.TrimSpace(p)) {
-		case "DEBUG":
-			m[DebugLevel] = true
-		case "INFO":
-			m[InfoLevel] = true
-		case "NOTICE":
-			m[NoticeLevel] = true
-		case "WARNING":
-			m[WarnLevel] = true
-		case "ERROR":
-			m[ErrorLevel] = true
-		case "CRIT", "CRITICAL":
-			m[CritLevel] = true
-		case "ALERT":
-			m[AlertLevel] = true
-		case "EMERG", "EMERGENCY":
-			m[EmergLevel] = true
-		case "FATAL":
-			m[FatalLevel] = true
+		if level, ok := levelFromName(p); ok {
+			m[level] = true
 		}
 	}
 	return m
 }
 
+// levelFromName resolves a level name as accepted by parseLevels.
+func levelFromName(name string) (Level, bool) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return DebugLevel, true
+	case "INFO":
+		return InfoLevel, true
+	case "NOTICE":
+		return NoticeLevel, true
+	case "WARNING":
+		return WarnLevel, true
+	case "ERROR":
+		return ErrorLevel, true
+	case "CRIT", "CRITICAL":
+		return CritLevel, true
+	case "ALERT":
+		return AlertLevel, true
+	case "EMERG", "EMERGENCY":
+		return EmergLevel, true
+	case "FATAL":
+		return FatalLevel, true
+	}
+	return 0, false
+}
+
+// SetLeveler drives level filtering from an external slog.Leveler, such as a
+// *slog.LevelVar shared with the rest of the application.
+// While set, a level is enabled when its slog equivalent is at or above
+// l.Level(), and the levels configured through Init are ignored.
+// NOTICE sits between INFO and WARN; CRIT, ALERT, EMERG and FATAL sit above ERROR.
+// Pass nil to return to the levels configured through Init.
+// Safe to call concurrently with logging.
+func SetLeveler(l slog.Leveler) {
+	if l == nil {
+		leveler.Store(nil)
+		return
+	}
+	leveler.Store(&levelerHolder{l: l})
+}
+
+// levelMask is a set of levels with bit 1<<level set for each member.
+type levelMask uint32
+
+// numLevels is one past the highest Level value.
+const numLevels = EmergLevel + 1
+
+func init() {
+	enabledMask.Store(uint32(maskOf(allLevelsEnabled())))
+}
+
+// maskOf converts a level set as returned by resolveLevels to a levelMask.
+func maskOf(levels map[Level]bool) levelMask {
+	var m levelMask
+	for level, enabled := range levels {
+		if enabled && level >= 0 && level < numLevels {
+			m |= 1 << level
+		}
+	}
+	return m
+}
+
+func (m levelMask) has(level Level) bool {
+	return level >= 0 && level < numLevels && m&(1<<level) != 0
+}
+
 // isLevelEnabled checks if a level is enabled for logging.
+// Disable overrides it for every level.
 func isLevelEnabled(level Level) bool {
-	return enabledLevels[level]
-}
-
-// newColorLogger returns a colored logger for the level.
-// If fileWriter is provided, logs are written to both console and file.
-func newColorLogger(out io.Writer, level string, showLevel bool, fileWriter io.Writer) *log.Logger {
-	colors := map[string]string{
-		"DEBUG":   "\033[36m",
-		"INFO":    "\033[32m",
-		"NOTICE":  "\033[34m",
-		"WARNING": "\033[33m",
-		"ERROR":   "\033[31m",
-		"CRIT":    "\033[91m",
-		"ALERT":   "\033[95m",
-		"EMERG":   "\033[97m",
-		"FATAL":   "\033[35m",
+	if muted.Load() {
+		return false
 	}
+	if h := leveler.Load(); h != nil {
+		return slogLevel(level) >= h.l.Level()
+	}
+	return levelMask(enabledMask.Load()).has(level)
+}
+
+// SetLevels replaces the enabled levels at runtime without reinitializing outputs.
+// A nil slice behaves like a nil Config.Levels: LOGGER_LEVELS when set, otherwise all levels.
+// Thread-safe for concurrent use.
+func SetLevels(levels []Level) {
+	enabledMask.Store(uint32(maskOf(resolveLevels(levels))))
+}
+
+// Disable mutes every level with a single atomic flag, e.g. around a
+// performance-sensitive section, leaving the configured levels untouched.
+// Fatal methods still exit, without writing their line. Audit is not a level
+// and keeps writing.
+// Thread-safe for concurrent use.
+func Disable() {
+	muted.Store(true)
+}
+
+// Enable undoes Disable; the levels enabled before Disable apply again,
+// including any SetLevels or SetLeveler changes made in between.
+// Thread-safe for concurrent use.
+func Enable() {
+	muted.Store(false)
+}
+
+// Enabled reports whether logging is on, i.e. Disable has not been called
+// since the last Enable. It does not consider levels.
+func Enabled() bool {
+	return !muted.Load()
+}
+
+// newColorLogger returns a colored console logger for the level.
+// The prefix color comes from the Config.ColorTheme palette.
+func newColorLogger(out io.Writer, level string, style LevelPrefixStyle) *log.Logger {
 	reset := "\033[0m"
 	prefix := ""
-	if showLevel {
-		prefix = fmt.Sprintf("%s[%s]%s", colors[level], level, reset)
+	if tag := levelTag(level, style); tag != "" {
+		prefix = levelColors[level] + tag + reset
+	}
+	if len(highlightRules) > 0 {
+		out = &highlightWriter{w: out, rules: highlightRules}
+	}
+	return log.New(out, prefixForLog(prefix), 0)
+}
+
+// newPlainLogger returns a non-colored logger for stdout/stderr output.
+func newPlainLogger(out io.Writer, level string, style LevelPrefixStyle) *log.Logger {
+	prefix := levelTag(level, style)
+	outWriter := out
+	if shouldUseSyslogPrefix() {
+		if syslogPrefix := syslogPrefixForLevel(level); syslogPrefix != "" {
+			outWriter = &syslogPrefixWriter{w: out, prefix: syslogPrefix}
+		}
+	}
+	return log.New(outWriter, prefixForLog(prefix), 0)
+}
+
+func prefixForLog(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return prefix + " "
+}
+
+func shouldUseSyslogPrefix() bool {
+	return os.Getenv("JOURNAL_STREAM") != ""
+}
+
+func syslogPrefixForLevel(level string) string {
+	switch level {
+	case "EMERG":
+		return "<0>"
+	case "ALERT":
+		return "<1>"
+	case "CRIT":
+		return "<2>"
+	case "DEBUG":
+		return "<7>"
+	case "INFO":
+		return "<6>"
+	case "NOTICE":
+		return "<5>"
+	case "WARNING":
+		return "<4>"
+	case "ERROR":
+		return "<3>"
+	case "FATAL":
+		return "<2>"
+	default:
+		return ""
+	}
+}
+
+// syslogPrefixWriter prepends the syslog priority prefix to each line.
+type syslogPrefixWriter struct {
+	w      io.Writer
+	prefix string
+}
+
+func (s *syslogPrefixWriter) Write(data []byte) (int, error) {
+	if s.prefix == "" {
+		return s.w.Write(data)
+	}
+	if len(data) == 0 {
+		return 0, nil
 	}
+	buf := make([]byte, 0, len(data)+len(s.prefix))
+	buf = append(buf, s.prefix...)
+	for i, b := range data {
+		buf = append(buf, b)
+		if b == '\n' && i != len(data)-1 {
+			buf = append(buf, s.prefix...)
+		}
+	}
+	if _, err := s.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// ansiEscape matches the SGR escape sequences produced by the color loggers.
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// highlightWriter colors pattern matches in each line before writing it to the console.
+type highlightWriter struct {
+	w     io.Writer
+	rules []HighlightRule
+}
+
+func (h *highlightWriter) Write(data []byte) (int, error) {
+	type span struct {
+		start, end int
+		color      string
+	}
+	// Existing escape sequences are claimed first so rules never match inside them.
+	var spans []span
+	for _, loc := range ansiEscape.FindAllIndex(data, -1) {
+		spans = append(spans, span{start: loc[0], end: loc[1]})
+	}
+	overlaps := func(start, end int) bool {
+		for _, s := range spans {
+			if start < s.end && s.start < end {
+				return true
+			}
+		}
+		return false
+	}
+	matched := false
+	for _, rule := range h.rules {
+		if rule.Pattern == nil {
+			continue
+		}
+		for _, loc := range rule.Pattern.FindAllIndex(data, -1) {
+			if loc[0] == loc[1] || overlaps(loc[0], loc[1]) {
+				continue
+			}
+			spans = append(spans, span{start: loc[0], end: loc[1], color: rule.Color})
+			matched = true
+		}
+	}
+	if !matched {
+		return h.w.Write(data)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	buf := make([]byte, 0, len(data)+len(spans)*8)
+	last := 0
+	for _, s := range spans {
+		if s.color == "" {
+			continue
+		}
+		buf = append(buf, data[last:s.start]...)
+		buf = append(buf, s.color...)
+		buf = append(buf, data[s.start:s.end]...)
+		buf = append(buf, "\033[0m"...)
+		last = s.end
+	}
+	buf = append(buf, data[last:]...)
+	if _, err := h.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// timestampLayout renders line timestamps the same way as log.LstdFlags.
+const timestampLayout = "2006/01/02 15:04:05 "
+
+// ErrWriteTimeout is reported to Config.OnWriteError when an output does not
+// accept a write within Config.WriteTimeout.
+var ErrWriteTimeout = errors.New("logger: write timed out")
+
+// withWriteTimeout wraps w so writes give up after timeout; a zero timeout returns w unchanged.
+func withWriteTimeout(w io.Writer, timeout time.Duration) io.Writer {
+	if timeout <= 0 {
+		return w
+	}
+	return &timeoutWriter{w: w, timeout: timeout}
+}
+
+// timeoutWriter performs each write in a goroutine and abandons it after timeout.
+// While an abandoned write is still blocked, further writes are dropped immediately
+// instead of piling up goroutines behind it. An abandoned write may still complete
+// later if the underlying writer recovers.
+type timeoutWriter struct {
+	w       io.Writer
+	timeout time.Duration
+	busy    atomic.Bool
+}
+
+func (t *timeoutWriter) Write(data []byte) (int, error) {
+	if !t.busy.CompareAndSwap(false, true) {
+		droppedLines.Add(1)
+		return 0, ErrWriteTimeout
+	}
+	// log.Logger reuses its buffer, so the goroutine needs its own copy.
+	buf := append([]byte(nil), data...)
+	done := make(chan error, 1)
+	go func() {
+		_, err := t.w.Write(buf)
+		t.busy.Store(false)
+		done <- err
+	}()
+
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	case <-timer.C:
+		droppedLines.Add(1)
+		return 0, ErrWriteTimeout
+	}
+}
+
+// multiWriter duplicates writes to every writer like io.MultiWriter, but keeps
+// going when one of them fails so a broken output cannot starve the others.
+// The returned error joins the errors of every failing writer.
+type multiWriter struct {
+	writers []io.Writer
+}
+
+func newMultiWriter(writers ...io.Writer) io.Writer {
+	return &multiWriter{writers: writers}
+}
+
+func (m *multiWriter) Write(data []byte) (int, error) {
+	var errs []error
+	for _, w := range m.writers {
+		if _, err := w.Write(data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(data), errors.Join(errs...)
+}
+
+// reportWriteError passes err to Config.OnWriteError. Must hold logMutex.
+func reportWriteError(err error) {
+	if err != nil && onWriteError != nil {
+		onWriteError(err)
+	}
+}
+
+// callerInfo describes a call site.
+type callerInfo struct {
+	tag      string // "package.Function:line", or "unknown"
+	function string // "package.Function"
+	file     string
+	line     int
+}
+
+var unknownCaller = callerInfo{tag: "unknown"}
+
+// getCallerInfo returns formatted caller information at the specified stack depth.
+// Returns "package.Function:line" format for better log clarity.
+func getCallerInfo(depth int) string {
+	return getCaller(depth + 1).tag
+}
+
+// getCaller returns the call site at the specified stack depth.
+// When CallerSkipPackages is set, frames inside those packages are skipped and
+// the first remaining frame is reported.
+func getCaller(depth int) callerInfo {
+	if len(callerSkipPrefixes) > 0 {
+		return getCallerSkipping(depth + 1)
+	}
+	pc, file, line, ok := runtime.Caller(depth)
+	if !ok {
+		return unknownCaller
+	}
+	if cached, ok := callerCache.Load(pc); ok {
+		return cached.(callerInfo)
+	}
+	caller := callerForPC(pc, file, line)
+	if callerCacheSize.Load() < maxCallerCacheEntries {
+		if _, loaded := callerCache.LoadOrStore(pc, caller); !loaded {
+			callerCacheSize.Add(1)
+		}
+	}
+	return caller
+}
+
+// maxCallerCacheEntries caps the caller cache so programs with huge numbers of
+// distinct call sites (e.g. generated code) cannot grow it without bound.
+const maxCallerCacheEntries = 4096
+
+var (
+	// callerCache maps a call-site PC to its callerInfo.
+	// A PC always resolves to the same function and line, so entries never go stale.
+	callerCache     sync.Map
+	callerCacheSize atomic.Int64
+)
+
+// callerForPC describes the function containing pc at the given file and line.
+func callerForPC(pc uintptr, file string, line int) callerInfo {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return unknownCaller
+	}
+	return newCallerInfo(fn.Name(), file, line)
+}
+
+// getCallerSkipping walks up the stack from depth to the first frame
+// outside callerSkipPrefixes, falling back to the frame at depth.
+func getCallerSkipping(depth int) callerInfo {
+	var pcs [32]uintptr
+	n := runtime.Callers(depth+1, pcs[:])
+	if n == 0 {
+		return unknownCaller
+	}
+	frames := runtime.CallersFrames(pcs[:n])
+	var first runtime.Frame
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if i == 0 {
+			first = frame
+		}
+		if !hasSkippedPrefix(frame.Function) {
+			return newCallerInfo(frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	if first.Function == "" {
+		return unknownCaller
+	}
+	return newCallerInfo(first.Function, first.File, first.Line)
+}
+
+func hasSkippedPrefix(function string) bool {
+	for _, prefix := range callerSkipPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// newCallerInfo describes a fully qualified function name, file and line,
+// tagged as "package.Function:line".
+func newCallerInfo(full, file string, line int) callerInfo {
+	// Strip package path, keep package.Function
+	lastSlash := strings.LastIndex(full, "/")
+	if lastSlash >= 0 && lastSlash+1 < len(full) {
+		full = full[lastSlash+1:]
+	}
+	return callerInfo{
+		tag:      fmt.Sprintf("%s:%d", full, line),
+		function: full,
+		file:     file,
+		line:     line,
+	}
+}
+
+// Record is a single log event, captured before it is rendered for an output.
+type Record struct {
+	// Time is when the event was logged.
+	Time time.Time
+	// Level is the event severity.
+	Level Level
+	// Caller is the "package.Function:line" call site; empty unless IncludeCallerTag is set.
+	Caller string
+	// CallerFunc, CallerFile and CallerLine are the parts of Caller: the
+	// "package.Function" name, the source file path and the line number.
+	// JSON output writes them as caller_func, caller_file and caller_line.
+	CallerFunc string
+	CallerFile string
+	CallerLine int
+	// Message is the formatted log message without fields.
+	Message string
+	// Status is the HTTP status code of an Api call; 0 for every other record.
+	// Text outputs render it as a "[200] " message prefix, JSON as a "status" field.
+	Status int
+	// Fields holds the structured key-value pairs in call order.
+	Fields []Field
+}
+
+// text returns the message as text outputs show it, with the Api status
+// code as a "[200] " prefix.
+func (rec Record) text() string {
+	if rec.Status != 0 {
+		return fmt.Sprintf("[%d] %s", rec.Status, rec.Message)
+	}
+	return rec.Message
+}
+
+// Field is a structured key-value pair attached to a Record.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// collectFields pairs up keyvals, skipping pairs whose key is not a string
+// and a trailing key without a value.
+func collectFields(keyvals []any) []Field {
+	if len(keyvals) < 2 {
+		return nil
+	}
+	fields := make([]Field, 0, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		fields = append(fields, Field{Key: key, Value: keyvals[i+1]})
+	}
+	return fields
+}
+
+// coder is implemented by errors that carry a machine-readable code.
+type coder interface {
+	Code() string
+}
+
+// resolveLazy replaces func() any and func() string values with their results.
+// It runs once per record after the level check, so expensive values passed as
+// closures cost nothing when the level is disabled, and every output sees the same value.
+// It runs without logMutex, so a closure may log.
+func resolveLazy(fields []Field) []Field {
+	for i, f := range fields {
+		switch fn := f.Value.(type) {
+		case func() any:
+			fields[i].Value = fn()
+		case func() string:
+			fields[i].Value = fn()
+		}
+	}
+	return fields
+}
+
+// withErrorCode appends a "code" field for the first error value whose chain
+// contains an error with a Code() string method, unless a "code" field is
+// already present. Only error values are inspected, so unrelated types that
+// happen to have a Code method are left alone.
+func withErrorCode(fields []Field) []Field {
+	if hasField(fields, "code") {
+		return fields
+	}
+	for _, f := range fields {
+		err, ok := f.Value.(error)
+		if !ok {
+			continue
+		}
+		var c coder
+		if errors.As(err, &c) {
+			return append(fields, Field{Key: "code", Value: c.Code()})
+		}
+	}
+	return fields
+}
+
+// SetGlobalFields replaces the key-value pairs appended to every line,
+// overriding Config.GlobalFields. Call with no arguments to clear them.
+// When DedupeFields is enabled, a call-site field with the same key takes precedence.
+// Thread-safe for concurrent use.
+func SetGlobalFields(keyvals ...any) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	globalFields = collectFields(keyvals)
+}
+
+// resolveProcessFields returns the pid and host fields requested by Config.
+func resolveProcessFields(includePID, includeHostname bool) []Field {
+	var fields []Field
+	if includePID {
+		fields = append(fields, Field{Key: "pid", Value: os.Getpid()})
+	}
+	if includeHostname {
+		if host, err := os.Hostname(); err == nil {
+			fields = append(fields, Field{Key: "host", Value: host})
+		}
+	}
+	return fields
+}
+
+// withGlobalFields appends the global fields, then the pid/host fields, after
+// the call-site fields. With DedupeFields enabled, globals whose key is already
+// present are skipped so call-site values win. Must hold logMutex.
+func withGlobalFields(fields []Field) []Field {
+	if len(globalFields) == 0 && len(processFields) == 0 {
+		return fields
+	}
+	merged := make([]Field, 0, len(fields)+len(globalFields)+len(processFields))
+	merged = append(merged, fields...)
+	for _, extra := range [][]Field{globalFields, processFields} {
+		for _, g := range extra {
+			if dedupeFields && hasField(fields, g.Key) {
+				continue
+			}
+			merged = append(merged, g)
+		}
+	}
+	return merged
+}
+
+func hasField(fields []Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// callFields pairs up call-site keyvals and resolves their lazy values. It
+// runs before logMutex is taken, so a lazy value may itself log.
+func callFields(keyvals []any) []Field {
+	return resolveLazy(collectFields(keyvals))
+}
+
+// buildFields turns the call-site fields from callFields into the fields of a
+// record, applying error codes, global fields, the goroutine ID, normalization
+// and MaxFields.
+// Must hold logMutex.
+func buildFields(fields []Field) []Field {
+	return limitFields(normalizeFields(withGoroutineID(withGlobalFields(withErrorCode(fields)))))
+}
+
+// moreFieldsKey is the key of the marker field that replaces the pairs
+// dropped by Config.MaxFields.
+const moreFieldsKey = "…"
+
+// moreFields counts the pairs dropped by Config.MaxFields. Text output
+// renders the marker as "…(+M more)".
+type moreFields int
+
+func (m moreFields) String() string {
+	return fmt.Sprintf("(+%d more)", int(m))
+}
+
+// limitFields keeps the first maxFields fields and appends a moreFields
+// marker counting the rest. Zero maxFields keeps every field.
+func limitFields(fields []Field) []Field {
+	if maxFields <= 0 || len(fields) <= maxFields {
+		return fields
+	}
+	limited := make([]Field, maxFields, maxFields+1)
+	copy(limited, fields)
+	return append(limited, Field{Key: moreFieldsKey, Value: moreFields(len(fields) - maxFields)})
+}
+
+// keySet converts a key list to a set; nil when keys is empty.
+func keySet(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return set
+}
+
+// dropKeys returns fields without the keys in deny, leaving fields untouched.
+func dropKeys(fields []Field, deny map[string]bool) []Field {
+	if len(deny) == 0 {
+		return fields
+	}
+	kept := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		if !deny[f.Key] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// normalizeFields applies the DedupeFields and SortFields options.
+// Deduplication is stable: each key keeps the position of its first occurrence
+// and the value of its last.
+func normalizeFields(fields []Field) []Field {
+	if len(fields) < 2 {
+		return fields
+	}
+	if dedupeFields {
+		index := make(map[string]int, len(fields))
+		deduped := fields[:0:0]
+		for _, f := range fields {
+			if i, ok := index[f.Key]; ok {
+				deduped[i].Value = f.Value
+				continue
+			}
+			index[f.Key] = len(deduped)
+			deduped = append(deduped, f)
+		}
+		fields = deduped
+	}
+	if sortFields {
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	}
+	return fields
+}
+
+// encodeFields formats fields as " key=value" pairs separated by spaces.
+// Line breaks in values follow the Config.AllowMultiline policy.
+func encodeFields(fields []Field) string {
+	return encodeConsoleFields(fields, "", false)
+}
+
+// fieldKeyColor dims field keys on a colorized console with Config.ColorFields.
+const fieldKeyColor = "\033[90m"
+
+// encodeConsoleFields is encodeFields for the console: each key is wrapped in
+// keyColor, an ANSI escape sequence (an empty keyColor leaves the keys plain),
+// and with humanized set, Bytes and Count values are shown with units.
+func encodeConsoleFields(fields []Field, keyColor string, humanized bool) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if more, ok := f.Value.(moreFields); ok {
+			parts = append(parts, moreFieldsKey+more.String())
+			continue
+		}
+		key := f.Key
+		if keyColor != "" {
+			key = keyColor + key + "\033[0m"
+		}
+		text, ok := "", false
+		if humanized {
+			text, ok = humanValue(f.Value)
+		}
+		if !ok {
+			text = formatValue(f.Value)
+		}
+		parts = append(parts, key+"="+fieldText(text))
+	}
+	return " " + strings.Join(parts, " ")
+}
+
+// messageWithFields joins msg and the fields rendered by encodeFields or
+// encodeConsoleFields, putting Config.FieldPrefix in front of a non-empty
+// field block that follows a non-empty message.
+func messageWithFields(msg, fields string) string {
+	if fields == "" {
+		return msg
+	}
+	fields = strings.TrimPrefix(fields, " ")
+	if msg == "" {
+		return fields
+	}
+	return msg + fieldPrefix + fields
+}
+
+// newlineEscaper writes line breaks inside field values as the literal
+// sequences \n and \r.
+var newlineEscaper = strings.NewReplacer("\r", `\r`, "\n", `\n`)
+
+// fieldText applies the multi-line policy to a rendered field value: line
+// breaks are escaped so every record stays on one line, unless
+// Config.AllowMultiline keeps them inside a "<<<" ... ">>>" block.
+func fieldText(text string) string {
+	if !strings.ContainsAny(text, "\r\n") {
+		return text
+	}
+	if allowMultiline {
+		return "<<<\n" + strings.TrimSuffix(text, "\n") + "\n>>>"
+	}
+	return newlineEscaper.Replace(text)
+}
+
+// formatValue renders a field value.
+// []byte is shown as text (or hex with Config.BytesAsHex) instead of a list of
+// numbers; errors and fmt.Stringers use their Error/String methods, with fmt
+// guarding against nil receivers; a nil value renders as "<nil>"; maps
+// render as {k1:v1 k2:v2} with keys in sorted order.
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return val
+	case []byte:
+		if bytesAsHex {
+			return hex.EncodeToString(val)
+		}
+		return string(val)
+	case error, fmt.Stringer:
+		return fmt.Sprint(val)
+	default:
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Map {
+			return formatMap(rv)
+		}
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// formatMap renders a map as {k1:v1 k2:v2}, ordered by the rendered key so
+// the same map always produces the same line. Keys and values go through
+// formatValue, so nested maps are sorted too.
+func formatMap(m reflect.Value) string {
+	type entry struct{ key, value string }
+	entries := make([]entry, 0, m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		entries = append(entries, entry{formatValue(iter.Key().Interface()), formatValue(iter.Value().Interface())})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].key != entries[j].key {
+			return entries[i].key < entries[j].key
+		}
+		return entries[i].value < entries[j].value
+	})
+	parts := make([]string, len(entries))
+	for i, e := range entries {
+		parts[i] = e.key + ":" + e.value
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+// levelLogger returns the log.Logger that writes console (and text file) output for a level.
+func levelLogger(level Level) *log.Logger {
+	switch level {
+	case DebugLevel:
+		return Debug
+	case InfoLevel:
+		return Info
+	case NoticeLevel:
+		return Notice
+	case WarnLevel:
+		return Warning
+	case ErrorLevel:
+		return Error
+	case CritLevel:
+		return Crit
+	case AlertLevel:
+		return Alert
+	case EmergLevel:
+		return Emerg
+	default:
+		return Fatal
+	}
+}
+
+// Output returns a writer to wherever level currently goes: its console stream
+// (including highlighting or journald prefixes) plus the text log file, unless
+// Config.Routing sends the level to RouteDiscard, and any LevelMirrors. Bytes
+// are written as-is, bypassing level filtering, timestamps, the level and
+// caller tags, fields and Sinks; WithOutput writers get a copy. The
+// destination is resolved on every Write, so the writer follows later Init
+// calls, and each Write holds the logger lock so it never interleaves with
+// log lines.
+func Output(level Level) io.Writer {
+	return levelOutput(level)
+}
+
+// levelOutput is the writer returned by Output.
+type levelOutput Level
+
+func (l levelOutput) Write(p []byte) (int, error) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	writers := []io.Writer{levelLogger(Level(l)).Writer()}
+	if text, ok := fileSink.(*textSink); ok && !fileSkip.has(Level(l)) {
+		writers = append(writers, text.w)
+	}
+	if mirror := mirrorSinks[Level(l)]; mirror != nil {
+		writers = append(writers, mirror.w)
+	}
+	for _, tee := range teeSinks {
+		writers = append(writers, tee.w)
+	}
+	return newMultiWriter(writers...).Write(p)
+}
 
-	// Combine console and file output if file writer is provided
-	if fileWriter != nil {
-		// Write colored output to console, plain output to file
-		return log.New(io.MultiWriter(out, &plainFileWriter{w: fileWriter, level: level}), prefixForLog(prefix), log.LstdFlags)
-	}
-	return log.New(out, prefixForLog(prefix), log.LstdFlags)
+// logMessage records a message with its key-value pairs and writes it to every output.
+// depth is the runtime.Caller depth of the call site as seen from the function calling logMessage.
+// Callers are expected to have checked that the level is enabled.
+// Thread-safe for concurrent use.
+func logMessage(level Level, depth int, msg string, keyvals []any) {
+	logStatusMessage(level, depth+1, 0, msg, keyvals)
 }
 
-// newPlainLogger returns a non-colored logger for stdout/stderr output.
-// If fileWriter is provided, logs are written to both console and file.
-func newPlainLogger(out io.Writer, level string, showLevel bool, fileWriter io.Writer) *log.Logger {
-	prefix := ""
-	if showLevel {
-		prefix = fmt.Sprintf("[%s]", level)
-	}
-	outWriter := out
-	if shouldUseSyslogPrefix() {
-		if syslogPrefix := syslogPrefixForLevel(level); syslogPrefix != "" {
-			outWriter = &syslogPrefixWriter{w: out, prefix: syslogPrefix}
-		}
+// logStatusMessage is logMessage for records carrying an Api status code.
+func logStatusMessage(level Level, depth int, status int, msg string, keyvals []any) {
+	if recordMessage(level, depth+1, time.Time{}, status, msg, keyvals) {
+		exitFatal()
 	}
-	if fileWriter != nil {
-		return log.New(io.MultiWriter(outWriter, &timestampWriter{w: fileWriter}), prefixForLog(prefix), 0)
-	}
-	return log.New(outWriter, prefixForLog(prefix), 0)
 }
 
-func prefixForLog(prefix string) string {
-	if prefix == "" {
-		return ""
+// logMessageAt is logMessage with the record time supplied by the caller.
+func logMessageAt(level Level, depth int, at time.Time, msg string, keyvals []any) {
+	if recordMessage(level, depth+1, at, 0, msg, keyvals) {
+		exitFatal()
 	}
-	return prefix + " "
 }
 
-func shouldUseSyslogPrefix() bool {
-	return os.Getenv("JOURNAL_STREAM") != ""
+// recordMessage builds the record for logStatusMessage and logMessageAt and
+// writes it under logMutex, unless Config.SingleThreaded skips the lock.
+// A zero at stamps the record with the current time. It reports whether the
+// line must end the process (see failFast), decided under the same lock.
+func recordMessage(level Level, depth int, at time.Time, status int, msg string, keyvals []any) bool {
+	fields := callFields(keyvals)
+	if !singleThreaded.Load() {
+		logMutex.Lock()
+		defer logMutex.Unlock()
+	}
+	writeRecord(buildRecord(level, depth+1, at, status, msg, fields))
+	return failFast(level)
 }
 
-func syslogPrefixForLevel(level string) string {
-	switch level {
-	case "EMERG":
-		return "<0>"
-	case "ALERT":
-		return "<1>"
-	case "CRIT":
-		return "<2>"
-	case "DEBUG":
-		return "<7>"
-	case "INFO":
-		return "<6>"
-	case "NOTICE":
-		return "<5>"
-	case "WARNING":
-		return "<4>"
-	case "ERROR":
-		return "<3>"
-	case "FATAL":
-		return "<2>"
-	default:
-		return ""
+// buildRecord assembles the redacted record for a call site, with depth as in
+// logMessage. A zero at stamps the record with the current time.
+// Must hold logMutex.
+func buildRecord(level Level, depth int, at time.Time, status int, msg string, fields []Field) Record {
+	if at.IsZero() {
+		at = nowFunc()
+	}
+	rec := Record{
+		Time:    at,
+		Level:   level,
+		Message: msg,
+		Status:  status,
+		Fields:  buildFields(fields),
 	}
+	if includeCallerTag {
+		caller := getCaller(depth + 1)
+		rec.Caller, rec.CallerFunc, rec.CallerFile, rec.CallerLine = caller.tag, caller.function, caller.file, caller.line
+	}
+	return redactRecord(rec)
 }
 
-// syslogPrefixWriter prepends the syslog priority prefix to each line.
-type syslogPrefixWriter struct {
-	w      io.Writer
-	prefix string
+// failFast reports whether logging at level must end the process, see
+// Config.FailFastLevel. FATAL is excluded because the Fatal methods exit themselves.
+// Must hold logMutex.
+func failFast(level Level) bool {
+	return failFastLevel != DebugLevel && level != FatalLevel &&
+		severity(level) >= severity(failFastLevel)
 }
 
-func (s *syslogPrefixWriter) Write(data []byte) (int, error) {
-	if s.prefix == "" {
-		return s.w.Write(data)
+// warnBeforeInit writes a one-time warning to stderr when a line is logged
+// before Init while the level's logger still discards its output, so a
+// missing Init does not lose logs silently.
+func warnBeforeInit(level Level) {
+	if initialized.Load() || levelLogger(level).Writer() != io.Discard {
+		return
 	}
-	if len(data) == 0 {
-		return 0, nil
+	if preInitWarned.CompareAndSwap(false, true) {
+		fmt.Fprintln(outStderr, "logger: Init not called; logs are discarded")
 	}
-	buf := make([]byte, 0, len(data)+len(s.prefix))
-	buf = append(buf, s.prefix...)
-	for i, b := range data {
-		buf = append(buf, b)
-		if b == '\n' && i != len(data)-1 {
-			buf = append(buf, s.prefix...)
-		}
+}
+
+// usableStream returns w, or io.Discard when w is a closed *os.File. Other
+// writers cannot be probed without risking a blocking write and are kept.
+func usableStream(w io.Writer) (io.Writer, error) {
+	f, ok := w.(*os.File)
+	if !ok {
+		return w, nil
 	}
-	if _, err := s.w.Write(buf); err != nil {
-		return 0, err
+	if _, err := f.Stat(); err != nil {
+		return io.Discard, err
 	}
-	return len(data), nil
+	return w, nil
 }
 
-// plainFileWriter wraps a file writer to strip ANSI color codes before writing.
-type plainFileWriter struct {
-	w     io.Writer
-	level string
-}
-
-func (p *plainFileWriter) Write(data []byte) (int, error) {
-	// Strip ANSI color codes (basic implementation)
-	s := string(data)
-	// Remove color codes like \033[36m and \033[0m
-	var result strings.Builder
-	inEscape := false
-	for i := 0; i < len(s); i++ {
-		if s[i] == '\033' && i+1 < len(s) && s[i+1] == '[' {
-			inEscape = true
-			continue
-		}
-		if inEscape {
-			if s[i] == 'm' {
-				inEscape = false
-			}
-			continue
-		}
-		result.WriteByte(s[i])
+// noticeStreamFallback logs that the named standard stream was unusable at
+// Init and its console output is being discarded. A nil err logs nothing.
+func noticeStreamFallback(name string, err error) {
+	if err == nil || !isLevelEnabled(NoticeLevel) {
+		return
 	}
+	logMessage(NoticeLevel, 2, name+" unavailable, console output discarded", []any{"error", err})
+}
 
-	// The log.Logger already adds the level prefix, so we just need to strip colors
-	// Don't add duplicate level prefix here
-	return p.w.Write([]byte(result.String()))
+// consoleTimestamp renders the console timestamp for t, followed by the time
+// in Config.DualTimeZone when set, e.g. "2024/03/09 14:05:06 (13:05:06 UTC) ".
+func consoleTimestamp(t time.Time) string {
+	ts := t.Format(timestampLayout)
+	if dualTimeZone != nil {
+		ts += "(" + t.In(dualTimeZone).Format("15:04:05 MST") + ") "
+	}
+	return ts
 }
 
-// timestampWriter prepends a timestamp to each log line for file outputs.
-// Used to keep timestamps in files while omitting them from stdout/stderr output.
-type timestampWriter struct {
-	w io.Writer
+// writeLine writes line and the line terminator through l.
+// log.Logger always ends output in "\n", so terminators without one bypass
+// Output and write the prefix and line directly; the logger's flags are skipped then.
+func writeLine(l *log.Logger, line string) error {
+	if strings.HasSuffix(lineTerminator, "\n") {
+		return l.Output(3, line+lineTerminator)
+	}
+	_, err := io.WriteString(l.Writer(), l.Prefix()+line+lineTerminator)
+	return err
 }
 
-func (t *timestampWriter) Write(data []byte) (int, error) {
-	ts := time.Now().Format("2006/01/02 15:04:05 ")
-	buf := make([]byte, 0, len(ts)+len(data))
-	buf = append(buf, ts...)
-	buf = append(buf, data...)
-	return t.w.Write(buf)
+// writeRecord renders rec for the console and file outputs, unless
+// Config.TriggerLevel holds it back. Must hold logMutex.
+func writeRecord(rec Record) {
+	if holdRecord(rec) {
+		return
+	}
+	emitRecord(rec)
 }
 
-// getCallerInfo returns formatted caller information at the specified stack depth.
-// Returns "package.Function" format for better log clarity.
-func getCallerInfo(depth int) string {
-	pc, _, line, ok := runtime.Caller(depth)
-	if !ok {
-		return "unknown"
+// emitRecord renders rec for every output. Must hold logMutex.
+func emitRecord(rec Record) {
+	warnBeforeInit(rec.Level)
+	countLine(rec.Level)
+	rec = withSequence(rec)
+	l := levelLogger(rec.Level)
+	colored := colorLoggers[l]
+	keyColor := ""
+	if colored && colorFields {
+		keyColor = fieldKeyColor
 	}
-	fn := runtime.FuncForPC(pc)
-	if fn == nil {
-		return "unknown"
+	line := messageWithFields(rec.text(), encodeConsoleFields(rec.Fields, keyColor, true))
+	if rec.Caller != "" {
+		line = fmt.Sprintf("[%s] %s", rec.Caller, line)
 	}
-	full := fn.Name()
-	// Strip package path, keep package.Function
-	lastSlash := strings.LastIndex(full, "/")
-	if lastSlash >= 0 && lastSlash+1 < len(full) {
-		full = full[lastSlash+1:]
+	if colored {
+		line = consoleTimestamp(rec.Time) + line
+	}
+	reportWriteError(writeLine(l, line))
+	if mirror := mirrorSinks[rec.Level]; mirror != nil {
+		reportWriteError(mirror.WriteRecord(rec))
+	}
+	if fileSink != nil && !fileSkip.has(rec.Level) {
+		fileRec := rec
+		fileRec.Fields = dropKeys(rec.Fields, fileFieldDenylist)
+		reportWriteError(fileSink.WriteRecord(fileRec))
+	}
+	for _, sink := range sinks {
+		reportWriteError(sink.WriteRecord(rec))
+	}
+	for _, tee := range teeSinks {
+		reportWriteError(tee.WriteRecord(rec))
 	}
-	return fmt.Sprintf("%s:%d", full, line)
 }
 
-func formatWithCaller(depth int, msg string) string {
-	if !includeCallerTag {
-		return msg
+// --- Formatted logging methods (fmt.Sprintf style) ---
+
+// sprintf formats like fmt.Sprintf. With Config.StrictFormat it also warns
+// about verb/argument mismatches, tagged with the caller of the logging function.
+func sprintf(format string, v ...any) string {
+	msg := fmt.Sprintf(format, v...)
+	if strictFormat.Load() && strings.Contains(msg, "%!") && isLevelEnabled(WarnLevel) && malformedFormat(format, v) {
+		// Frames: getCallerInfo, sprintf, the logging function, its caller.
+		caller := getCallerInfo(3)
+		logMessage(WarnLevel, 2, fmt.Sprintf("malformed format call at %s: %q", caller, format), nil)
 	}
-	caller := getCallerInfo(depth + 1)
-	return fmt.Sprintf("[%s] %s", caller, msg)
+	return msg
 }
 
-// encodeFields formats key-value pairs as "key=value" strings.
-func encodeFields(keyvals ...any) string {
-	if len(keyvals) == 0 {
-		return ""
+// malformedFormat reports whether fmt reports an error for format and args.
+// Arguments are formatted through formatCheck, which writes nothing, so a
+// "%!" that comes from argument text (e.g. "100%!") is not mistaken for one
+// of fmt's error forms such as "%!(EXTRA ...)" or "%!(MISSING)".
+func malformedFormat(format string, args []any) bool {
+	var bad bool
+	checked := make([]any, len(args))
+	for i, arg := range args {
+		checked[i] = formatCheck{value: arg, bad: &bad}
 	}
-	parts := make([]string, 0, len(keyvals)/2)
-	for i := 0; i+1 < len(keyvals); i += 2 {
-		key, ok := keyvals[i].(string)
-		if !ok {
-			continue
-		}
-		parts = append(parts, fmt.Sprintf("%s=%v", key, keyvals[i+1]))
-	}
-	if len(parts) == 0 {
-		return ""
-	}
-	return " " + strings.Join(parts, " ")
+	return strings.Contains(fmt.Sprintf(format, checked...), "%!") || bad
 }
 
-// --- Formatted logging methods (fmt.Sprintf style) ---
+// formatCheck stands in for a format argument in malformedFormat. It formats
+// the real value with the same directive and sets bad when fmt rejects the
+// verb for it, e.g. "%!d(string=x)".
+type formatCheck struct {
+	value any
+	bad   *bool
+}
+
+func (c formatCheck) Format(f fmt.State, verb rune) {
+	out := fmt.Sprintf(fmt.FormatString(f, verb), c.value)
+	if strings.HasPrefix(out, "%!"+string(verb)+"(") {
+		*c.bad = true
+	}
+}
 
 // Debugf logs a debug message formatted with fmt.Sprintf.
 // Caller tagging is included when enabled in Init.
@@ -478,12 +2013,7 @@ func Debugf(format string, v ...any) {
 	if !isLevelEnabled(DebugLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprintf(format, v...)
-	msg = formatWithCaller(2, msg)
-	Debug.Println(msg)
+	logMessage(DebugLevel, 2, sprintf(format, v...), nil)
 }
 
 // Infof logs an informational message formatted with fmt.Sprintf.
@@ -493,12 +2023,7 @@ func Infof(format string, v ...any) {
 	if !isLevelEnabled(InfoLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprintf(format, v...)
-	msg = formatWithCaller(2, msg)
-	Info.Println(msg)
+	logMessage(InfoLevel, 2, sprintf(format, v...), nil)
 }
 
 // Noticef logs a notice message formatted with fmt.Sprintf.
@@ -508,12 +2033,7 @@ func Noticef(format string, v ...any) {
 	if !isLevelEnabled(NoticeLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprintf(format, v...)
-	msg = formatWithCaller(2, msg)
-	Notice.Println(msg)
+	logMessage(NoticeLevel, 2, sprintf(format, v...), nil)
 }
 
 // Warnf logs a warning message formatted with fmt.Sprintf.
@@ -523,12 +2043,7 @@ func Warnf(format string, v ...any) {
 	if !isLevelEnabled(WarnLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprintf(format, v...)
-	msg = formatWithCaller(2, msg)
-	Warning.Println(msg)
+	logMessage(WarnLevel, 2, sprintf(format, v...), nil)
 }
 
 // Errorf logs an error message formatted with fmt.Sprintf.
@@ -538,12 +2053,7 @@ func Errorf(format string, v ...any) {
 	if !isLevelEnabled(ErrorLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprintf(format, v...)
-	msg = formatWithCaller(2, msg)
-	Error.Println(msg)
+	logMessage(ErrorLevel, 2, sprintf(format, v...), nil)
 }
 
 // Critf logs a critical message formatted with fmt.Sprintf.
@@ -553,12 +2063,7 @@ func Critf(format string, v ...any) {
 	if !isLevelEnabled(CritLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprintf(format, v...)
-	msg = formatWithCaller(2, msg)
-	Crit.Println(msg)
+	logMessage(CritLevel, 2, sprintf(format, v...), nil)
 }
 
 // Alertf logs an alert message formatted with fmt.Sprintf.
@@ -568,12 +2073,7 @@ func Alertf(format string, v ...any) {
 	if !isLevelEnabled(AlertLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprintf(format, v...)
-	msg = formatWithCaller(2, msg)
-	Alert.Println(msg)
+	logMessage(AlertLevel, 2, sprintf(format, v...), nil)
 }
 
 // Emergf logs an emergency message formatted with fmt.Sprintf.
@@ -583,28 +2083,32 @@ func Emergf(format string, v ...any) {
 	if !isLevelEnabled(EmergLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprintf(format, v...)
-	msg = formatWithCaller(2, msg)
-	Emerg.Println(msg)
+	logMessage(EmergLevel, 2, sprintf(format, v...), nil)
 }
 
-// Fatalf logs a fatal message formatted with fmt.Sprintf and then calls os.Exit(1).
+// Fatalf logs a fatal message formatted with fmt.Sprintf and then exits with Config.FatalExitCode (default 1).
 // Caller tagging is included when enabled in Init.
 // Thread-safe for concurrent use.
 func Fatalf(format string, v ...any) {
 	if !isLevelEnabled(FatalLevel) {
-		os.Exit(1)
+		exitFatal()
+		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
+	logMessage(FatalLevel, 2, sprintf(format, v...), nil)
+	exitFatal()
+}
 
-	msg := fmt.Sprintf(format, v...)
-	msg = formatWithCaller(2, msg)
-	Fatal.Println(msg)
-	os.Exit(1)
+// FatalfCode logs a fatal message formatted with fmt.Sprintf and then exits with code,
+// regardless of Config.FatalExitCode. OnExit handlers run before exiting.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func FatalfCode(code int, format string, v ...any) {
+	if !isLevelEnabled(FatalLevel) {
+		exit(code)
+		return
+	}
+	logMessage(FatalLevel, 2, sprintf(format, v...), nil)
+	exit(code)
 }
 
 // --- Plain logging methods (Println style) ---
@@ -616,12 +2120,7 @@ func Debugln(v ...any) {
 	if !isLevelEnabled(DebugLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprint(v...)
-	msg = formatWithCaller(2, msg)
-	Debug.Println(msg)
+	logMessage(DebugLevel, 2, fmt.Sprint(v...), nil)
 }
 
 // Infoln logs an informational message by joining arguments with fmt.Sprint.
@@ -631,12 +2130,7 @@ func Infoln(v ...any) {
 	if !isLevelEnabled(InfoLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprint(v...)
-	msg = formatWithCaller(2, msg)
-	Info.Println(msg)
+	logMessage(InfoLevel, 2, fmt.Sprint(v...), nil)
 }
 
 // Noticeln logs a notice message by joining arguments with fmt.Sprint.
@@ -646,12 +2140,7 @@ func Noticeln(v ...any) {
 	if !isLevelEnabled(NoticeLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprint(v...)
-	msg = formatWithCaller(2, msg)
-	Notice.Println(msg)
+	logMessage(NoticeLevel, 2, fmt.Sprint(v...), nil)
 }
 
 // Warnln logs a warning message by joining arguments with fmt.Sprint.
@@ -661,12 +2150,7 @@ func Warnln(v ...any) {
 	if !isLevelEnabled(WarnLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprint(v...)
-	msg = formatWithCaller(2, msg)
-	Warning.Println(msg)
+	logMessage(WarnLevel, 2, fmt.Sprint(v...), nil)
 }
 
 // Errorln logs an error message by joining arguments with fmt.Sprint.
@@ -676,12 +2160,7 @@ func Errorln(v ...any) {
 	if !isLevelEnabled(ErrorLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprint(v...)
-	msg = formatWithCaller(2, msg)
-	Error.Println(msg)
+	logMessage(ErrorLevel, 2, fmt.Sprint(v...), nil)
 }
 
 // Critln logs a critical message by joining arguments with fmt.Sprint.
@@ -691,12 +2170,7 @@ func Critln(v ...any) {
 	if !isLevelEnabled(CritLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprint(v...)
-	msg = formatWithCaller(2, msg)
-	Crit.Println(msg)
+	logMessage(CritLevel, 2, fmt.Sprint(v...), nil)
 }
 
 // Alertln logs an alert message by joining arguments with fmt.Sprint.
@@ -706,12 +2180,7 @@ func Alertln(v ...any) {
 	if !isLevelEnabled(AlertLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprint(v...)
-	msg = formatWithCaller(2, msg)
-	Alert.Println(msg)
+	logMessage(AlertLevel, 2, fmt.Sprint(v...), nil)
 }
 
 // Emergln logs an emergency message by joining arguments with fmt.Sprint.
@@ -721,31 +2190,25 @@ func Emergln(v ...any) {
 	if !isLevelEnabled(EmergLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprint(v...)
-	msg = formatWithCaller(2, msg)
-	Emerg.Println(msg)
+	logMessage(EmergLevel, 2, fmt.Sprint(v...), nil)
 }
 
-// Fatalln logs a fatal message by joining arguments with fmt.Sprint and then calls os.Exit(1).
+// Fatalln logs a fatal message by joining arguments with fmt.Sprint and then exits with Config.FatalExitCode (default 1).
 // Caller tagging is included when enabled in Init.
 // Thread-safe for concurrent use.
 func Fatalln(v ...any) {
 	if !isLevelEnabled(FatalLevel) {
-		os.Exit(1)
+		exitFatal()
+		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	msg := fmt.Sprint(v...)
-	msg = formatWithCaller(2, msg)
-	Fatal.Println(msg)
-	os.Exit(1)
+	logMessage(FatalLevel, 2, fmt.Sprint(v...), nil)
+	exitFatal()
 }
 
 // --- Structured logging methods (key-value pairs) ---
+//
+// When a value is an error whose chain includes an error with a Code() string
+// method, a code=... field is added automatically unless "code" is already set.
 
 // DebugKV logs a debug message with structured key-value pairs.
 // Caller tagging is included when enabled in Init.
@@ -754,13 +2217,7 @@ func DebugKV(msg string, keyvals ...any) {
 	if !isLevelEnabled(DebugLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	fields := encodeFields(keyvals...)
-	line := fmt.Sprintf("%s%s", msg, fields)
-	line = formatWithCaller(2, line)
-	Debug.Println(line)
+	logMessage(DebugLevel, 2, msg, keyvals)
 }
 
 // InfoKV logs an info message with structured key-value pairs.
@@ -770,13 +2227,7 @@ func InfoKV(msg string, keyvals ...any) {
 	if !isLevelEnabled(InfoLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	fields := encodeFields(keyvals...)
-	line := fmt.Sprintf("%s%s", msg, fields)
-	line = formatWithCaller(2, line)
-	Info.Println(line)
+	logMessage(InfoLevel, 2, msg, keyvals)
 }
 
 // NoticeKV logs a notice message with structured key-value pairs.
@@ -786,13 +2237,7 @@ func NoticeKV(msg string, keyvals ...any) {
 	if !isLevelEnabled(NoticeLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	fields := encodeFields(keyvals...)
-	line := fmt.Sprintf("%s%s", msg, fields)
-	line = formatWithCaller(2, line)
-	Notice.Println(line)
+	logMessage(NoticeLevel, 2, msg, keyvals)
 }
 
 // WarnKV logs a warning message with structured key-value pairs.
@@ -802,13 +2247,7 @@ func WarnKV(msg string, keyvals ...any) {
 	if !isLevelEnabled(WarnLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	fields := encodeFields(keyvals...)
-	line := fmt.Sprintf("%s%s", msg, fields)
-	line = formatWithCaller(2, line)
-	Warning.Println(line)
+	logMessage(WarnLevel, 2, msg, keyvals)
 }
 
 // ErrorKV logs an error message with structured key-value pairs.
@@ -818,13 +2257,7 @@ func ErrorKV(msg string, keyvals ...any) {
 	if !isLevelEnabled(ErrorLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	fields := encodeFields(keyvals...)
-	line := fmt.Sprintf("%s%s", msg, fields)
-	line = formatWithCaller(2, line)
-	Error.Println(line)
+	logMessage(ErrorLevel, 2, msg, keyvals)
 }
 
 // CritKV logs a critical message with structured key-value pairs.
@@ -834,13 +2267,7 @@ func CritKV(msg string, keyvals ...any) {
 	if !isLevelEnabled(CritLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	fields := encodeFields(keyvals...)
-	line := fmt.Sprintf("%s%s", msg, fields)
-	line = formatWithCaller(2, line)
-	Crit.Println(line)
+	logMessage(CritLevel, 2, msg, keyvals)
 }
 
 // AlertKV logs an alert message with structured key-value pairs.
@@ -850,13 +2277,7 @@ func AlertKV(msg string, keyvals ...any) {
 	if !isLevelEnabled(AlertLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	fields := encodeFields(keyvals...)
-	line := fmt.Sprintf("%s%s", msg, fields)
-	line = formatWithCaller(2, line)
-	Alert.Println(line)
+	logMessage(AlertLevel, 2, msg, keyvals)
 }
 
 // EmergKV logs an emergency message with structured key-value pairs.
@@ -866,36 +2287,91 @@ func EmergKV(msg string, keyvals ...any) {
 	if !isLevelEnabled(EmergLevel) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	fields := encodeFields(keyvals...)
-	line := fmt.Sprintf("%s%s", msg, fields)
-	line = formatWithCaller(2, line)
-	Emerg.Println(line)
+	logMessage(EmergLevel, 2, msg, keyvals)
 }
 
-// FatalKV logs a fatal message with structured key-value pairs and then calls os.Exit(1).
+// FatalKV logs a fatal message with structured key-value pairs and then exits with Config.FatalExitCode (default 1).
 // Caller tagging is included when enabled in Init.
 // Thread-safe for concurrent use.
 func FatalKV(msg string, keyvals ...any) {
 	if !isLevelEnabled(FatalLevel) {
-		os.Exit(1)
+		exitFatal()
+		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
+	logMessage(FatalLevel, 2, msg, keyvals)
+	exitFatal()
+}
+
+// --- Map logging methods (format string plus field map) ---
+
+// mapKeyvals flattens fields into key-value pairs sorted by key, since map
+// iteration order is random.
+func mapKeyvals(fields map[string]any) []any {
+	if len(fields) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	keyvals := make([]any, 0, len(keys)*2)
+	for _, k := range keys {
+		keyvals = append(keyvals, k, fields[k])
+	}
+	return keyvals
+}
+
+// Debugm logs a debug message formatted with fmt.Sprintf, followed by fields
+// rendered like DebugKV with keys in sorted order.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func Debugm(format string, fields map[string]any, v ...any) {
+	if !isLevelEnabled(DebugLevel) {
+		return
+	}
+	logMessage(DebugLevel, 2, sprintf(format, v...), mapKeyvals(fields))
+}
+
+// Infom logs an informational message formatted with fmt.Sprintf, followed by fields
+// rendered like InfoKV with keys in sorted order.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func Infom(format string, fields map[string]any, v ...any) {
+	if !isLevelEnabled(InfoLevel) {
+		return
+	}
+	logMessage(InfoLevel, 2, sprintf(format, v...), mapKeyvals(fields))
+}
+
+// Warnm logs a warning message formatted with fmt.Sprintf, followed by fields
+// rendered like WarnKV with keys in sorted order.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func Warnm(format string, fields map[string]any, v ...any) {
+	if !isLevelEnabled(WarnLevel) {
+		return
+	}
+	logMessage(WarnLevel, 2, sprintf(format, v...), mapKeyvals(fields))
+}
 
-	fields := encodeFields(keyvals...)
-	line := fmt.Sprintf("%s%s", msg, fields)
-	line = formatWithCaller(2, line)
-	Fatal.Println(line)
-	os.Exit(1)
+// Errorm logs an error message formatted with fmt.Sprintf, followed by fields
+// rendered like ErrorKV with keys in sorted order.
+// Caller tagging is included when enabled in Init.
+// Thread-safe for concurrent use.
+func Errorm(format string, fields map[string]any, v ...any) {
+	if !isLevelEnabled(ErrorLevel) {
+		return
+	}
+	logMessage(ErrorLevel, 2, sprintf(format, v...), mapKeyvals(fields))
 }
 
 // --- API logging methods (HTTP status code based) ---
 
 // Api logs an HTTP API call with automatic level selection based on status code.
 // Status codes are mapped to levels: 2xx->INFO, 4xx->WARNING, 5xx->ERROR.
+// The code is kept as Record.Status: text outputs show "[200] msg", while JSON
+// output gets a numeric "status" field next to the plain "msg".
 // Thread-safe for concurrent use.
 //
 // Example:
@@ -908,19 +2384,28 @@ func Api(statusCode int, msg string) {
 	if !isLevelEnabled(level) {
 		return
 	}
-	logMutex.Lock()
-	defer logMutex.Unlock()
-
-	logMsg := fmt.Sprintf("[%d] %s", statusCode, msg)
-	logMsg = formatWithCaller(2, logMsg)
+	logStatusMessage(level, 2, statusCode, msg, nil)
+}
 
-	switch level {
-	case InfoLevel:
-		Info.Println(logMsg)
-	case WarnLevel:
-		Warning.Println(logMsg)
-	case ErrorLevel:
-		Error.Println(logMsg)
+// ApiLevel is Api with the level chosen by the caller instead of derived from
+// statusCode, for responses that are an expected outcome at that call site,
+// such as a 404 from a cache lookup. The line is filtered by the given level;
+// at FatalLevel it exits with Config.FatalExitCode like Fatalf. Values outside
+// the defined levels are ignored.
+// Thread-safe for concurrent use.
+//
+// Example:
+//
+//	logger.ApiLevel(404, logger.InfoLevel, "cache miss")
+func ApiLevel(statusCode int, level Level, msg string) {
+	if level < 0 || level >= numLevels {
+		return
+	}
+	if isLevelEnabled(level) {
+		logStatusMessage(level, 2, statusCode, msg, nil)
+	}
+	if level == FatalLevel {
+		exitFatal()
 	}
 }
 
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInfoAt_UsesSuppliedTime(t *testing.T) {
+	defer discardOutput()()
+	setNow(t, time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	past := time.Date(2021, 7, 1, 8, 30, 0, 0, time.UTC)
+	dir := t.TempDir()
+	textPath, jsonPath := filepath.Join(dir, "app.log"), filepath.Join(dir, "app.json")
+
+	Init(Config{Levels: AllLevels(), FilePath: textPath})
+	InfoAt(past, "order placed", "order", 17)
+	Infof("live")
+	Close()
+	Init(Config{Levels: AllLevels(), FilePath: jsonPath, Format: FormatJSON})
+	ErrorAt(past, "order failed")
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	text, err := os.ReadFile(textPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	want := "2021/07/01 08:30:00 order placed order=17\n2024/03/09 14:05:06 live\n"
+	if string(text) != want {
+		t.Fatalf("unexpected text file:\n got %q\nwant %q", text, want)
+	}
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.HasPrefix(string(content), `{"time":"2021-07-01T08:30:00Z","level":"ERROR","msg":"order failed"}`) {
+		t.Fatalf("JSON time should be the supplied time, got: %s", content)
+	}
+}
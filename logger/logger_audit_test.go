@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAudit_WritesWhenAllLevelsDisabled(t *testing.T) {
+	defer discardOutput()()
+	t.Setenv("LOGGER_LEVELS", "FATAL")
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	auditPath := filepath.Join(dir, "audit.log")
+	setNow(t, time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+
+	Init(Config{Levels: []Level{}, FilePath: logPath, AuditFilePath: auditPath, AuditSequence: true})
+	defer Close()
+	SetLeveler(slog.Level(100))
+	defer SetLeveler(nil)
+
+	Infof("filtered")
+	Audit("role granted", "user", "alice", "role", "admin")
+	Audit("login", "user", "bob")
+
+	audit, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("failed to read audit file: %v", err)
+	}
+	want := "2024-03-09T14:05:06Z seq=1 role granted user=alice role=admin\n" +
+		"2024-03-09T14:05:06Z seq=2 login user=bob\n"
+	if string(audit) != want {
+		t.Fatalf("expected %q, got %q", want, audit)
+	}
+	main, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if len(main) != 0 {
+		t.Fatalf("audit lines should not reach the main log, got: %q", main)
+	}
+}
+
+func TestAudit_FallsBackToStderr(t *testing.T) {
+	var stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = io.Discard
+	outStderr = &stderrBuf
+
+	Init(Config{Levels: []Level{}})
+	Audit("config changed", "key", "timeout")
+
+	got := stderrBuf.String()
+	if !strings.HasPrefix(got, "[AUDIT] ") || !strings.HasSuffix(got, " config changed key=timeout\n") {
+		t.Fatalf("unexpected audit line: %q", got)
+	}
+	if strings.Contains(got, "seq=") {
+		t.Fatalf("sequence numbers should be opt-in, got: %q", got)
+	}
+}
+
+func TestSnapshot_ReopensAuditFileClosedByInit(t *testing.T) {
+	defer discardOutput()()
+	var stderrBuf bytes.Buffer
+	outStderr = &stderrBuf
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	setNow(t, time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	Init(Config{Levels: AllLevels(), AuditFilePath: auditPath, AuditSequence: true})
+	defer Close()
+
+	Audit("before")
+	restore := Snapshot()
+	Init(Config{Levels: AllLevels()})
+	Audit("inside")
+	restore()
+	Audit("after")
+
+	audit, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("failed to read audit file: %v", err)
+	}
+	want := "2024-03-09T14:05:06Z seq=1 before\n" +
+		"2024-03-09T14:05:06Z seq=2 after\n"
+	if string(audit) != want {
+		t.Fatalf("expected %q, got %q", want, audit)
+	}
+	if got := stderrBuf.String(); got != "[AUDIT] 2024-03-09T14:05:06Z inside\n" {
+		t.Fatalf("expected only the inside line on stderr, got %q", got)
+	}
+}
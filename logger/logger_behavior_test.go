@@ -2,8 +2,14 @@ package logger
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestStdoutStderrRouting(t *testing.T) {
@@ -158,3 +164,458 @@ func TestSyslogPrefixForLevels(t *testing.T) {
 		}
 	}
 }
+
+func TestHighlights_ColorsMatchedToken(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = io.Discard
+	outStderr = &buf
+
+	red := "\033[31m"
+	Init(Config{
+		Levels:   []Level{ErrorLevel},
+		Colorize: true,
+		Highlights: []HighlightRule{
+			{Pattern: regexp.MustCompile(`error=\S+`), Color: red},
+			{Pattern: regexp.MustCompile(`timeout`), Color: "\033[33m"},
+		},
+	})
+
+	ErrorKV("request failed", "error", "timeout", "path", "/api")
+
+	got := buf.String()
+	if !strings.Contains(got, red+"error=timeout\033[0m") {
+		t.Fatalf("expected error field to be highlighted, got: %q", got)
+	}
+	if strings.Contains(got, "\033[33mtimeout") {
+		t.Fatalf("overlapping match should keep the first rule's color, got: %q", got)
+	}
+	if strings.Contains(got, red+"path") {
+		t.Fatalf("unmatched text should not be highlighted, got: %q", got)
+	}
+}
+
+func TestHighlights_IgnoredWithoutColorize(t *testing.T) {
+	var buf bytes.Buffer
+	oldStderr := outStderr
+	defer func() { outStderr = oldStderr }()
+	outStderr = &buf
+
+	Init(Config{
+		Levels:     []Level{ErrorLevel},
+		Highlights: []HighlightRule{{Pattern: regexp.MustCompile(`boom`), Color: "\033[31m"}},
+	})
+
+	Errorf("boom")
+
+	if got := buf.String(); strings.Contains(got, "\033[") {
+		t.Fatalf("highlights should not apply when Colorize is false, got: %q", got)
+	}
+}
+
+func TestStderrThreshold_ErrorLevel(t *testing.T) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = &stderrBuf
+
+	Init(Config{Levels: AllLevels(), StderrThreshold: ErrorLevel})
+
+	Noticef("notice-line")
+	Warnf("warn-line")
+	Errorf("error-line")
+	Critf("crit-line")
+
+	if got := stdoutBuf.String(); !strings.Contains(got, "notice-line") || !strings.Contains(got, "warn-line") {
+		t.Fatalf("stdout should hold levels below ERROR, got: %q", got)
+	}
+	if got := stderrBuf.String(); !strings.Contains(got, "error-line") || !strings.Contains(got, "crit-line") {
+		t.Fatalf("stderr should hold ERROR and above, got: %q", got)
+	}
+	if strings.Contains(stderrBuf.String(), "warn-line") {
+		t.Fatalf("warn should not reach stderr, got: %q", stderrBuf.String())
+	}
+}
+
+func TestSingleStream_EverythingToStdout(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	var stdoutBuf, stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = &stderrBuf
+
+	Init(Config{Levels: AllLevels(), SingleStream: true, Colorize: true, IncludeLevelPrefix: true, StderrThreshold: InfoLevel})
+	defer Init(Config{Levels: AllLevels()})
+
+	Infof("info-line")
+	Warnf("warn-line")
+	Critf("crit-line")
+
+	out := stdoutBuf.String()
+	for _, want := range []string{"info-line", "warn-line", "crit-line"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("stdout should hold %q, got: %q", want, out)
+		}
+	}
+	if !strings.Contains(timestampPattern.ReplaceAllString(ansiEscape.ReplaceAllString(out, ""), ""), "[WARNING] warn-line") {
+		t.Fatalf("prefix and color composition should still apply, got: %q", out)
+	}
+	if stderrBuf.Len() != 0 {
+		t.Fatalf("stderr should stay empty, got: %q", stderrBuf.String())
+	}
+}
+
+func TestSingleThreaded_WritesEveryLineInOrder(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = &stdoutBuf
+	logPath := filepath.Join(t.TempDir(), "cli.log")
+
+	Init(Config{Levels: AllLevels(), SingleThreaded: true, FilePath: logPath, IncludeLevelPrefix: true})
+	for i := 0; i < 100; i++ {
+		InfoKV("step", "i", i)
+	}
+	Errorf("done")
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	lines := strings.Split(strings.TrimSuffix(stdoutBuf.String(), "\n"), "\n")
+	if len(lines) != 101 {
+		t.Fatalf("expected 101 console lines, got %d", len(lines))
+	}
+	for i, line := range lines[:100] {
+		if want := fmt.Sprintf("[INFO] step i=%d", i); line != want {
+			t.Fatalf("line %d: expected %q, got %q", i, want, line)
+		}
+	}
+	if lines[100] != "[ERROR] done" {
+		t.Fatalf("unexpected last line: %q", lines[100])
+	}
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if strings.Count(string(content), "\n") != 101 {
+		t.Fatalf("file should hold every line, got: %q", content)
+	}
+}
+
+func TestStderrThreshold_DefaultIsWarn(t *testing.T) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = &stderrBuf
+
+	Init(Config{Levels: AllLevels()})
+
+	Noticef("notice-line")
+	Warnf("warn-line")
+
+	if !strings.Contains(stdoutBuf.String(), "notice-line") || !strings.Contains(stderrBuf.String(), "warn-line") {
+		t.Fatalf("expected default NOTICE->stdout, WARNING->stderr; got stdout=%q stderr=%q", stdoutBuf.String(), stderrBuf.String())
+	}
+}
+
+func TestStrictFormat_WarnsOnVerbMismatch(t *testing.T) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = &stderrBuf
+
+	Init(Config{Levels: AllLevels(), StrictFormat: true})
+
+	// Spread the args so go vet does not reject the deliberate mismatch.
+	args := []any{"not a number"}
+	Errorf("count: %d", args...)
+
+	got := stderrBuf.String()
+	if !strings.Contains(got, "count: %!d(string=not a number)") {
+		t.Fatalf("best-effort message should still be logged, got: %q", got)
+	}
+	if !strings.Contains(got, "malformed format call at logger.TestStrictFormat_WarnsOnVerbMismatch:") ||
+		!strings.Contains(got, `"count: %d"`) {
+		t.Fatalf("expected malformed format warning with caller, got: %q", got)
+	}
+}
+
+func TestStrictFormat_IgnoresPercentInArguments(t *testing.T) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = &stderrBuf
+
+	Init(Config{Levels: AllLevels(), StrictFormat: true})
+	defer Init(Config{Levels: AllLevels()})
+
+	Infof("progress %s", "100%!")
+	// An argument whose own text holds a fmt error is not the caller's mistake.
+	inner := []any{"x"}
+	Infof("nested %v", fmt.Errorf("%d", inner...))
+
+	if got := stderrBuf.String(); got != "" {
+		t.Fatalf("argument text should not trigger a warning, got: %q", got)
+	}
+	if got := stdoutBuf.String(); got != "progress 100%!\nnested %!d(string=x)\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+
+	extra := []any{"a", "b"}
+	Infof("only %s", extra...)
+	missing := []any{}
+	Infof("need %s", missing...)
+	if got := strings.Count(stderrBuf.String(), "malformed format call"); got != 2 {
+		t.Fatalf("expected EXTRA and MISSING to warn, got: %q", stderrBuf.String())
+	}
+}
+
+func TestStrictFormat_DefaultOff(t *testing.T) {
+	var stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = io.Discard
+	outStderr = &stderrBuf
+
+	Init(Config{Levels: AllLevels()})
+
+	args := []any{"not a number"}
+	Errorf("count: %d", args...)
+
+	if got := stderrBuf.String(); strings.Contains(got, "malformed format call") {
+		t.Fatalf("warning should only fire in strict mode, got: %q", got)
+	}
+}
+
+var timestampPattern = regexp.MustCompile(`\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} `)
+
+func TestLevelPrefixStyle_AllLevels(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	full := map[Level]string{
+		DebugLevel: "[DEBUG]", InfoLevel: "[INFO]", NoticeLevel: "[NOTICE]",
+		WarnLevel: "[WARNING]", ErrorLevel: "[ERROR]", CritLevel: "[CRIT]",
+		AlertLevel: "[ALERT]", EmergLevel: "[EMERG]", FatalLevel: "[FATAL]",
+	}
+	short := map[Level]string{
+		DebugLevel: "D", InfoLevel: "I", NoticeLevel: "N", WarnLevel: "W", ErrorLevel: "E",
+		CritLevel: "C", AlertLevel: "A", EmergLevel: "M", FatalLevel: "F",
+	}
+	cases := []struct {
+		style LevelPrefixStyle
+		want  map[Level]string
+	}{
+		{LevelPrefixFull, full},
+		{LevelPrefixShort, short},
+		{LevelPrefixNone, nil},
+	}
+	for _, tc := range cases {
+		for _, colorize := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/colorize=%v", tc.style, colorize), func(t *testing.T) {
+				var buf bytes.Buffer
+				oldStdout, oldStderr := outStdout, outStderr
+				defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+				outStdout, outStderr = &buf, &buf
+
+				Init(Config{Levels: AllLevels(), Colorize: colorize, IncludeLevelPrefix: true, LevelPrefixStyle: tc.style})
+				for _, level := range AllLevels() {
+					buf.Reset()
+					logMessage(level, 1, "msg", nil)
+					// Colorized loggers also print a timestamp; drop it with the colors.
+					line := timestampPattern.ReplaceAllString(ansiEscape.ReplaceAllString(buf.String(), ""), "")
+					want := "msg\n"
+					if tc.want != nil {
+						want = tc.want[level] + " msg\n"
+					}
+					if line != want {
+						t.Fatalf("level %s: expected %q, got %q", level, want, line)
+					}
+					if colorize && tc.want != nil && !strings.HasPrefix(buf.String(), "\033[") {
+						t.Fatalf("level %s: prefix should be colorized, got %q", level, buf.String())
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPadLevelPrefix_AlignsMessages(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+	logPath := filepath.Join(t.TempDir(), "app.log")
+
+	want := []string{"[INFO]    msg", "[WARNING] msg", "[ERROR]   msg", "[CRIT]    msg"}
+	for _, colorize := range []bool{false, true} {
+		buf.Reset()
+		Init(Config{Levels: AllLevels(), Colorize: colorize, IncludeLevelPrefix: true, PadLevelPrefix: true, FilePath: logPath})
+		Infof("msg")
+		Warnf("msg")
+		Errorf("msg")
+		Critf("msg")
+		Close()
+
+		lines := strings.Split(strings.TrimSuffix(ansiEscape.ReplaceAllString(buf.String(), ""), "\n"), "\n")
+		for i, line := range lines {
+			if got := timestampPattern.ReplaceAllString(line, ""); got != want[i] {
+				t.Fatalf("colorize=%v line %d: expected %q, got %q", colorize, i, want[i], got)
+			}
+		}
+	}
+	defer Init(Config{Levels: AllLevels()})
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	fileLines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(fileLines) != 8 {
+		t.Fatalf("expected 8 file lines, got %q", content)
+	}
+	for i, line := range fileLines[:4] {
+		ts := timestampPattern.FindString(line)
+		if ts == "" || !strings.HasPrefix(line, ts) || line[len(ts):] != want[i] {
+			t.Fatalf("file line should be a timestamp plus %q, got %q", want[i], line)
+		}
+	}
+}
+
+func TestLowercaseLevels_PrefixJSONAndEnvParsing(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	t.Setenv("LOGGER_LEVELS", "info,error")
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = &stdoutBuf
+	logPath := filepath.Join(t.TempDir(), "app.json")
+
+	Init(Config{IncludeLevelPrefix: true, LowercaseLevels: true, FilePath: logPath, Format: FormatJSON})
+	Debugf("hidden")
+	Infof("ready")
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	if got := stdoutBuf.String(); got != "[info] ready\n" {
+		t.Fatalf("expected lower-case prefix and LOGGER_LEVELS=info,error to apply, got: %q", got)
+	}
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), `"level":"info"`) {
+		t.Fatalf("JSON level should be lower case, got: %q", content)
+	}
+	if InfoLevel.String() != "INFO" {
+		t.Fatalf("Level.String should keep the upper-case name, got %q", InfoLevel.String())
+	}
+}
+
+func TestColorTheme_LightDiffersFromDark(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+	defer Init(Config{Levels: AllLevels()})
+
+	prefixColor := func(theme ColorTheme, custom map[Level]string, level Level) string {
+		buf.Reset()
+		Init(Config{Levels: AllLevels(), Colorize: true, IncludeLevelPrefix: true, ColorTheme: theme, LevelColors: custom})
+		logMessage(level, 1, "msg", nil)
+		return ansiEscape.FindString(buf.String())
+	}
+	for _, level := range []Level{InfoLevel, EmergLevel} {
+		dark, light := prefixColor(ColorThemeDark, nil, level), prefixColor(ColorThemeLight, nil, level)
+		if dark == "" || light == "" || dark == light {
+			t.Fatalf("%s: light theme should use a different color, dark=%q light=%q", level, dark, light)
+		}
+	}
+	if got := prefixColor(ColorThemeLight, nil, EmergLevel); got == "\033[97m" {
+		t.Fatalf("light theme should not use bright white for EMERG")
+	}
+
+	custom := map[Level]string{InfoLevel: "\033[38;5;208m"}
+	if got := prefixColor(ColorThemeCustom, custom, InfoLevel); got != "\033[38;5;208m" {
+		t.Fatalf("custom INFO color should apply, got %q", got)
+	}
+	if got := prefixColor(ColorThemeCustom, custom, ErrorLevel); got != "\033[31m" {
+		t.Fatalf("custom theme should fall back to dark colors, got %q", got)
+	}
+}
+
+func TestColorFields_ColorsKeysOnConsoleOnly(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = io.Discard
+	logPath := filepath.Join(t.TempDir(), "app.log")
+
+	Init(Config{Levels: AllLevels(), Colorize: true, ColorFields: true, FilePath: logPath})
+	defer Close()
+
+	InfoKV("user login", "user", "alice", "attempts", 2)
+
+	got := stdoutBuf.String()
+	if !strings.Contains(got, "user login \033[90muser\033[0m=alice \033[90mattempts\033[0m=2") {
+		t.Fatalf("expected colored keys and plain message/values, got: %q", got)
+	}
+	if strings.Contains(got, "\033[90muser login") {
+		t.Fatalf("message should not be colored, got: %q", got)
+	}
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if strings.Contains(string(content), "\033[") || !strings.Contains(string(content), "user login user=alice attempts=2") {
+		t.Fatalf("file should stay plain, got: %q", content)
+	}
+}
+
+func TestColorFields_IgnoredWithoutColorize(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout := outStdout
+	defer func() { outStdout = oldStdout }()
+	outStdout = &stdoutBuf
+
+	Init(Config{Levels: AllLevels(), ColorFields: true})
+	InfoKV("user login", "user", "alice")
+
+	if got := stdoutBuf.String(); got != "user login user=alice\n" {
+		t.Fatalf("expected plain output, got: %q", got)
+	}
+}
+
+func TestDualTimeZone_ConsoleShowsBothTimes(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = io.Discard
+	cet := time.FixedZone("CET", 3600)
+	setNow(t, time.Date(2024, 3, 9, 14, 5, 6, 0, cet))
+	logPath := filepath.Join(t.TempDir(), "app.log")
+
+	Init(Config{Levels: AllLevels(), Colorize: true, DualTimeZone: time.UTC, FilePath: logPath})
+	defer Close()
+	Infof("incident")
+
+	if got := ansiEscape.ReplaceAllString(stdoutBuf.String(), ""); got != "2024/03/09 14:05:06 (13:05:06 UTC) incident\n" {
+		t.Fatalf("unexpected console line: %q", got)
+	}
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if string(content) != "2024/03/09 14:05:06 incident\n" {
+		t.Fatalf("file should keep a single timestamp, got: %q", content)
+	}
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"io"
+	"log"
+	"runtime"
+	"testing"
+)
+
+// getCallerInfoUncached mirrors getCallerInfo without the PC cache.
+func getCallerInfoUncached(depth int) string {
+	pc, file, line, ok := runtime.Caller(depth)
+	if !ok {
+		return "unknown"
+	}
+	return callerForPC(pc, file, line).tag
+}
+
+func BenchmarkCallerInfo(b *testing.B) {
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = getCallerInfo(1)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = getCallerInfoUncached(1)
+		}
+	})
+}
+
+func BenchmarkInfof_CallerTag(b *testing.B) {
+	Info = log.New(io.Discard, "", 0)
+	enableLevels(InfoLevel)
+	prevInclude := includeCallerTag
+	includeCallerTag = true
+	defer func() { includeCallerTag = prevInclude }()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Infof("request %d", i)
+	}
+}
+
+func BenchmarkIsLevelEnabled(b *testing.B) {
+	SetLevels([]Level{InfoLevel, ErrorLevel})
+	defer SetLevels(AllLevels())
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = isLevelEnabled(DebugLevel)
+			_ = isLevelEnabled(InfoLevel)
+		}
+	})
+}
+
+func BenchmarkInfof_Locking(b *testing.B) {
+	for _, tc := range []struct {
+		name           string
+		singleThreaded bool
+	}{
+		{"locked", false},
+		{"single-threaded", true},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			Info = log.New(io.Discard, "", 0)
+			enableLevels(InfoLevel)
+			prev := singleThreaded.Load()
+			singleThreaded.Store(tc.singleThreaded)
+			defer singleThreaded.Store(prev)
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Infof("request %d", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBinaryFrame_RoundTrip(t *testing.T) {
+	rec := Record{
+		Time:    time.Unix(1700000000, 123456789),
+		Level:   CritLevel,
+		Caller:  "main.run:42",
+		Message: "disk failure, \"sda\"\nretrying",
+		Fields:  []Field{{Key: "disk", Value: "sda"}, {Key: "attempt", Value: 3}},
+	}
+
+	var buf bytes.Buffer
+	buf.Write(encodeFrame(rec))
+	buf.Write(encodeFrame(Record{Time: time.Unix(0, 1), Level: InfoLevel, Message: "second"}))
+
+	got, err := DecodeFrame(&buf)
+	if err != nil {
+		t.Fatalf("DecodeFrame failed: %v", err)
+	}
+	if !got.Time.Equal(rec.Time) || got.Level != rec.Level || got.Caller != rec.Caller || got.Message != rec.Message {
+		t.Fatalf("decoded record mismatch: got %+v, want %+v", got, rec)
+	}
+	if len(got.Fields) != 2 || got.Fields[0] != (Field{Key: "disk", Value: "sda"}) || got.Fields[1] != (Field{Key: "attempt", Value: "3"}) {
+		t.Fatalf("decoded fields mismatch: %+v", got.Fields)
+	}
+
+	second, err := DecodeFrame(&buf)
+	if err != nil || second.Message != "second" || len(second.Fields) != 0 {
+		t.Fatalf("expected second frame, got %+v (err %v)", second, err)
+	}
+	if _, err := DecodeFrame(&buf); err != io.EOF {
+		t.Fatalf("expected io.EOF after last frame, got %v", err)
+	}
+}
+
+func TestBinaryFrame_Truncated(t *testing.T) {
+	frame := encodeFrame(Record{Time: time.Now(), Level: InfoLevel, Message: "hello"})
+	_, err := DecodeFrame(bytes.NewReader(frame[:len(frame)-2]))
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestBinaryFrame_UnsupportedVersion(t *testing.T) {
+	frame := encodeFrame(Record{Time: time.Now(), Level: InfoLevel, Message: "hello"})
+	frame[4] = 99
+	if _, err := DecodeFrame(bytes.NewReader(frame)); !errors.Is(err, ErrInvalidFrame) {
+		t.Fatalf("expected ErrInvalidFrame, got %v", err)
+	}
+}
+
+func TestFileLogging_BinaryFormat(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.bin")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, Format: FormatBinary})
+	InfoKV("request completed", "status", 200, "path", "/api")
+	Errorf("boom")
+	Close()
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+	defer f.Close()
+
+	first, err := DecodeFrame(f)
+	if err != nil {
+		t.Fatalf("failed to decode first frame: %v", err)
+	}
+	if first.Level != InfoLevel || first.Message != "request completed" || len(first.Fields) != 2 || first.Fields[0].Value != "200" {
+		t.Fatalf("unexpected first record: %+v", first)
+	}
+	second, err := DecodeFrame(f)
+	if err != nil || second.Level != ErrorLevel || second.Message != "boom" {
+		t.Fatalf("unexpected second record: %+v (err %v)", second, err)
+	}
+	if _, err := DecodeFrame(f); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
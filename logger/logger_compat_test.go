@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestPrint_DefaultLevelRouting(t *testing.T) {
+	var infoBuf, warnBuf bytes.Buffer
+	oldStdout := outStdout
+	defer func() { outStdout = oldStdout }()
+	outStdout = &infoBuf
+
+	Init(Config{Levels: AllLevels(), IncludeCallerTag: true})
+	Warning = log.New(&warnBuf, "", 0)
+
+	Print("a", 1, 2)
+	Printf("b=%d", 3)
+	Println("c", 4)
+
+	lines := strings.Split(strings.TrimSpace(infoBuf.String()), "\n")
+	want := []string{"a1 2", "b=3", "c 4"}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d INFO lines, got: %q", len(want), infoBuf.String())
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "[logger.TestPrint_DefaultLevelRouting:") || !strings.HasSuffix(line, "] "+want[i]) {
+			t.Fatalf("line %d: expected caller tag and %q, got %q", i, want[i], line)
+		}
+	}
+
+	warn := WarnLevel
+	Init(Config{Levels: AllLevels(), DefaultLevel: &warn})
+	Warning = log.New(&warnBuf, "", 0)
+	Println("now a warning")
+	if got := warnBuf.String(); got != "now a warning\n" {
+		t.Fatalf("DefaultLevel should route Println to WARNING, got: %q", got)
+	}
+}
+
+func TestPrint_DefaultLevelDebug(t *testing.T) {
+	defer Snapshot()()
+	var debugBuf, infoBuf bytes.Buffer
+	debug := DebugLevel
+	Init(Config{Levels: AllLevels(), DefaultLevel: &debug})
+	Debug = log.New(&debugBuf, "", 0)
+	Info = log.New(&infoBuf, "", 0)
+
+	Printf("verbose %d", 1)
+
+	if got := debugBuf.String(); got != "verbose 1\n" || infoBuf.Len() != 0 {
+		t.Fatalf("DefaultLevel DebugLevel should route Printf to DEBUG, got debug=%q info=%q", got, infoBuf.String())
+	}
+	if got := CurrentConfig().DefaultLevel; got == nil || *got != DebugLevel {
+		t.Fatalf("CurrentConfig should report DEBUG, got %v", got)
+	}
+	loaded, err := LoadConfig(writeConfigFile(t, `{"default_level": "DEBUG"}`))
+	if err != nil || loaded.DefaultLevel == nil || *loaded.DefaultLevel != DebugLevel {
+		t.Fatalf("expected default_level DEBUG to load as a set DebugLevel, got %v (%v)", loaded.DefaultLevel, err)
+	}
+}
+
+func TestPanic_LogsAtCritAndPanics(t *testing.T) {
+	var buf bytes.Buffer
+	Crit = log.New(&buf, "", 0)
+	enableLevels(CritLevel)
+
+	cases := []struct {
+		name string
+		call func()
+		want string
+	}{
+		{"Panic", func() { Panic("disk ", "gone") }, "disk gone"},
+		{"Panicf", func() { Panicf("code %d", 7) }, "code 7"},
+		{"Panicln", func() { Panicln("a", "b") }, "a b"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			defer func() {
+				if r := recover(); r != tc.want {
+					t.Fatalf("expected panic %q, got %v", tc.want, r)
+				}
+				if got := buf.String(); got != tc.want+"\n" {
+					t.Fatalf("expected CRIT line %q, got %q", tc.want, got)
+				}
+			}()
+			tc.call()
+		})
+	}
+}
+
+func TestPanic_PanicsWhenCritDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	Crit = log.New(&buf, "", 0)
+	SetLevels([]Level{ErrorLevel})
+	defer SetLevels(AllLevels())
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("expected panic even with CRIT disabled, got %v", r)
+		}
+		if buf.Len() != 0 {
+			t.Fatalf("disabled CRIT should not log, got: %q", buf.String())
+		}
+	}()
+	Panic("boom")
+}
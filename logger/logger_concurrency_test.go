@@ -376,3 +376,51 @@ func TestConcurrency_RealTimeProgress(t *testing.T) {
 		t.Fatalf("expected %d goroutines completed, got %d", numGoroutines, completedGoroutines.Load())
 	}
 }
+
+// TestConcurrency_SequenceContiguous verifies that IncludeSequence numbers
+// lines contiguously in output order while many goroutines log, and that
+// filtered lines do not consume a number.
+func TestConcurrency_SequenceContiguous(t *testing.T) {
+	defer discardOutput()()
+	collector := &recordingSink{}
+	Init(Config{
+		Levels:          []Level{InfoLevel, WarnLevel},
+		IncludeSequence: true,
+		Sinks:           []Sink{collector},
+	})
+	defer Init(Config{Levels: AllLevels()})
+
+	const goroutines, perGoroutine = 10, 50
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				InfoKV("tick", "g", id)
+				Debugf("filtered %d", i)
+				if i%10 == 0 {
+					Warnf("warn %d", i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	want := goroutines * (perGoroutine + perGoroutine/10)
+	if len(collector.records) != want {
+		t.Fatalf("expected %d records, got %d", want, len(collector.records))
+	}
+	var prev uint64
+	for i, rec := range collector.records {
+		last := rec.Fields[len(rec.Fields)-1]
+		seq, ok := last.Value.(uint64)
+		if last.Key != "seq" || !ok {
+			t.Fatalf("record %d: expected trailing seq field, got %+v", i, rec.Fields)
+		}
+		if i > 0 && seq != prev+1 {
+			t.Fatalf("record %d: expected seq %d, got %d", i, prev+1, seq)
+		}
+		prev = seq
+	}
+}
@@ -0,0 +1,463 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "logger.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfig_Valid(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"levels": ["INFO", "error", "critical"],
+		"colorize": true,
+		"file_path": "/tmp/app.log",
+		"include_level_prefix": true,
+		"level_prefix_style": "short",
+		"stderr_threshold": "ERROR",
+		"format": "csv",
+		"highlights": [{"pattern": "error=\\S+", "color": "\u001b[31m"}]
+	}`)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if !reflect.DeepEqual(config.Levels, []Level{InfoLevel, ErrorLevel, CritLevel}) {
+		t.Fatalf("unexpected levels: %v", config.Levels)
+	}
+	if !config.Colorize || config.FilePath != "/tmp/app.log" || !config.IncludeLevelPrefix {
+		t.Fatalf("unexpected flags: %+v", config)
+	}
+	if config.LevelPrefixStyle != LevelPrefixShort {
+		t.Fatalf("unexpected level prefix style: %v", config.LevelPrefixStyle)
+	}
+	if config.StderrThreshold != ErrorLevel || config.Format != FormatCSV {
+		t.Fatalf("unexpected threshold/format: %v %v", config.StderrThreshold, config.Format)
+	}
+	if len(config.Highlights) != 1 || !config.Highlights[0].Pattern.MatchString("error=boom") {
+		t.Fatalf("unexpected highlights: %+v", config.Highlights)
+	}
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected not-exist error, got %v", err)
+	}
+}
+
+func TestLoadConfig_InvalidLevel(t *testing.T) {
+	path := writeConfigFile(t, `{"levels": ["INFO", "VERBOSE"]}`)
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), `unknown level "VERBOSE"`) {
+		t.Fatalf("expected unknown level error, got %v", err)
+	}
+}
+
+func TestLoadConfig_UnknownField(t *testing.T) {
+	path := writeConfigFile(t, `{"levles": ["INFO"]}`)
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "levles") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestLevel_TextRoundTrip(t *testing.T) {
+	for _, level := range AllLevels() {
+		text, err := level.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%v) failed: %v", level, err)
+		}
+		var got Level
+		if err := got.UnmarshalText(text); err != nil || got != level {
+			t.Fatalf("round trip of %v gave %v (err %v)", level, got, err)
+		}
+	}
+}
+
+func TestWatchConfig_ReloadsLevels(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &syncBuffer{buf: &stdoutBuf}
+	outStderr = io.Discard
+
+	path := writeConfigFile(t, `{"levels": ["INFO", "NOTICE"]}`)
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	Init(config)
+
+	stop, err := WatchConfig(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WatchConfig failed: %v", err)
+	}
+	defer stop()
+
+	if isLevelEnabled(DebugLevel) {
+		t.Fatal("debug should start disabled")
+	}
+	if err := os.WriteFile(path, []byte(`{"levels": ["DEBUG", "INFO", "NOTICE"]}`), 0644); err != nil {
+		t.Fatalf("failed to rewrite config: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("failed to bump mtime: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !isLevelEnabled(DebugLevel) {
+		if time.Now().After(deadline) {
+			t.Fatal("debug level was not enabled after config change")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	stop()
+
+	if got := stdoutBuf.String(); !strings.Contains(got, "config reloaded from "+path) {
+		t.Fatalf("expected reload notice, got: %q", got)
+	}
+}
+
+func TestReloadConfig_KeepsUnloadableFields(t *testing.T) {
+	defer discardOutput()()
+	defer Snapshot()()
+	dir := t.TempDir()
+	collector := &recordingSink{}
+	Init(Config{
+		Levels:           AllLevels(),
+		FilePath:         filepath.Join(dir, "old.log"),
+		IncludeCallerTag: true,
+		Sinks:            []Sink{collector},
+		RedactPatterns:   []*regexp.Regexp{regexp.MustCompile(`secret\d+`)},
+	})
+
+	newPath := filepath.Join(dir, "new.log")
+	reloadConfig(writeConfigFile(t, `{"file_path": "`+filepath.ToSlash(newPath)+`"}`))
+	Infof("token secret123")
+
+	if logFilePath != newPath {
+		t.Fatalf("expected the reload to switch to %s, got %q", newPath, logFilePath)
+	}
+	if !includeCallerTag {
+		t.Fatalf("options missing from the file should keep their current value")
+	}
+	last := collector.records[len(collector.records)-1]
+	if last.Message != "token ***" {
+		t.Fatalf("expected sinks and redaction to survive the reload, got %+v", last)
+	}
+	content, err := os.ReadFile(newPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if strings.Contains(string(content), "secret123") {
+		t.Fatalf("new log file should be redacted, got: %q", content)
+	}
+}
+
+// TestReloadConfig_ConcurrentLogging is meant for -race: path-changing
+// reloads must not race with goroutines that keep logging.
+func TestReloadConfig_ConcurrentLogging(t *testing.T) {
+	defer discardOutput()()
+	defer Snapshot()()
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")}
+	Init(Config{Levels: AllLevels(), FilePath: paths[0], FlushInterval: time.Millisecond})
+	defer Close()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				InfoKV("working", "goroutine", g, "i", i)
+				Warnf("slow step %d", i)
+			}
+		}()
+	}
+	for i := 1; i <= 10; i++ {
+		reloadConfig(writeConfigFile(t, `{"file_path": "`+filepath.ToSlash(paths[i%2])+`"}`))
+	}
+	close(stop)
+	wg.Wait()
+
+	Infof("after reloads")
+	if err := Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	content, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.HasSuffix(string(content), "after reloads\n") {
+		t.Fatalf("the last reload should leave %s in use, got tail %q", paths[0], content[max(0, len(content)-80):])
+	}
+}
+
+func TestWatchConfig_StopEndsWatcher(t *testing.T) {
+	path := writeConfigFile(t, `{}`)
+	stop, err := WatchConfig(path, time.Millisecond)
+	if err != nil {
+		t.Fatalf("WatchConfig failed: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		stop()
+		stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stop did not return")
+	}
+}
+
+func TestWatchConfig_MissingFile(t *testing.T) {
+	if _, err := WatchConfig(filepath.Join(t.TempDir(), "missing.json"), time.Second); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected not-exist error, got %v", err)
+	}
+}
+
+// syncBuffer serializes access to a bytes.Buffer shared with background goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf *bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func TestNewConfig_ComposesOptions(t *testing.T) {
+	config := NewConfig(
+		WithLevels(InfoLevel, ErrorLevel),
+		WithFile("/var/log/app.log"),
+		WithColor(),
+		WithCaller(),
+		WithJSON(),
+	)
+
+	want := Config{
+		Levels:           []Level{InfoLevel, ErrorLevel},
+		FilePath:         "/var/log/app.log",
+		Colorize:         true,
+		IncludeCallerTag: true,
+		Format:           FormatJSON,
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Fatalf("unexpected config:\n got %+v\nwant %+v", config, want)
+	}
+}
+
+func TestNewConfig_LaterOptionsOverride(t *testing.T) {
+	config := NewConfig(
+		WithLevels(DebugLevel),
+		WithFile("first.log"),
+		WithLevels(WarnLevel, ErrorLevel),
+		WithFile("second.log"),
+	)
+
+	if !reflect.DeepEqual(config.Levels, []Level{WarnLevel, ErrorLevel}) {
+		t.Fatalf("later WithLevels should win, got %v", config.Levels)
+	}
+	if config.FilePath != "second.log" {
+		t.Fatalf("later WithFile should win, got %q", config.FilePath)
+	}
+	if !reflect.DeepEqual(NewConfig(), Config{}) {
+		t.Fatalf("NewConfig without options should return the zero Config")
+	}
+}
+
+func TestValidate_ValidConfig(t *testing.T) {
+	config := Config{
+		Levels:          AllLevels(),
+		FilePath:        filepath.Join(t.TempDir(), "app.log"),
+		Colorize:        true,
+		ColorFields:     true,
+		StderrThreshold: ErrorLevel,
+		FlushInterval:   time.Second,
+		Header:          []any{"service", "api"},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	if err := (Config{}).Validate(); err != nil {
+		t.Fatalf("zero Config should be valid, got %v", err)
+	}
+}
+
+func TestValidate_FailureModes(t *testing.T) {
+	dir := t.TempDir()
+	notDir := filepath.Join(dir, "file")
+	if err := os.WriteFile(notDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	readOnly := filepath.Join(dir, "ro")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"unknown level", Config{Levels: []Level{InfoLevel, Level(42)}}, "unknown level 42"},
+		{"stderr threshold", Config{StderrThreshold: Level(-1)}, "StderrThreshold is an unknown level -1"},
+		{"fail fast level", Config{FailFastLevel: Level(99)}, "FailFastLevel is an unknown level 99"},
+		{"unknown format", Config{Format: Format(99)}, "unknown Format 99"},
+		{"unknown prefix style", Config{LevelPrefixStyle: LevelPrefixStyle(9)}, "unknown LevelPrefixStyle 9"},
+		{"negative max fields", Config{MaxFields: -1}, "MaxFields must not be negative"},
+		{"negative write timeout", Config{WriteTimeout: -time.Second}, "WriteTimeout must not be negative"},
+		{"negative flush interval", Config{FlushInterval: -time.Second}, "FlushInterval must not be negative"},
+		{"header with json", Config{Format: FormatJSON, Header: []any{"k", "v"}}, "Header is only written by the text format"},
+		{"severity markers with csv", Config{Format: FormatCSV, SeverityMarkers: true}, "SeverityMarkers are only written by the text format"},
+		{"unknown severity marker level", Config{SeverityMarkerLevel: Level(42)}, "SeverityMarkerLevel is an unknown level"},
+		{"color fields without color", Config{ColorFields: true}, "ColorFields requires Colorize"},
+		{"route without writer", Config{Routing: map[Level]RouteSpec{DebugLevel: {Dest: RouteWriter}}}, "RouteWriter without a Writer"},
+		{"route to missing file", Config{Routing: map[Level]RouteSpec{DebugLevel: {Dest: RouteFile}}}, "RouteFile without FilePath"},
+		{"single-threaded flusher", Config{SingleThreaded: true, FlushInterval: time.Second}, "SingleThreaded cannot be combined with FlushInterval"},
+		{"missing directory", Config{FilePath: filepath.Join(dir, "missing", "app.log")}, "FilePath directory"},
+		{"parent is a file", Config{FilePath: filepath.Join(notDir, "app.log")}, "is not a directory"},
+		{"read-only directory", Config{AuditFilePath: filepath.Join(readOnly, "audit.log")}, "AuditFilePath directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate_JoinsAllErrors(t *testing.T) {
+	err := Config{MaxFields: -1, FilePath: filepath.Join(t.TempDir(), "missing", "app.log")}.Validate()
+	if err == nil || !strings.Contains(err.Error(), "MaxFields") {
+		t.Fatalf("expected MaxFields error, got %v", err)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("missing directory should wrap fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestInitE_KeepsConfigurationOnError(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout := outStdout
+	defer func() { outStdout = oldStdout }()
+	outStdout = &buf
+
+	if err := InitE(Config{Levels: []Level{InfoLevel}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := InitE(Config{Levels: []Level{Level(42)}, MaxFields: -1}); err == nil {
+		t.Fatal("expected validation error")
+	}
+	Infof("still configured")
+	defer Init(Config{Levels: AllLevels()})
+
+	if !strings.Contains(buf.String(), "still configured") {
+		t.Fatalf("failed InitE should keep the previous configuration, got: %q", buf.String())
+	}
+}
+
+func TestCurrentConfig_ReflectsRuntimeState(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.log")
+
+	Init(Config{Levels: []Level{InfoLevel, ErrorLevel}, FilePath: logPath, Format: FormatJSON, IncludeCallerTag: true})
+	defer Close()
+
+	got := CurrentConfig()
+	if !reflect.DeepEqual(got.Levels, []Level{InfoLevel, ErrorLevel}) || got.FilePath != logPath ||
+		got.Format != FormatJSON || !got.IncludeCallerTag {
+		t.Fatalf("unexpected initial config: %+v", got)
+	}
+	if got.DefaultLevel == nil || *got.DefaultLevel != InfoLevel || got.StderrThreshold != WarnLevel || got.LineTerminator != "\n" || got.FatalExitCode != 1 {
+		t.Fatalf("defaults should be resolved, got %+v", got)
+	}
+
+	SetLevels([]Level{DebugLevel, WarnLevel})
+	SetGlobalFields("region", "eu")
+	defer SetGlobalFields()
+	got = CurrentConfig()
+	if !reflect.DeepEqual(got.Levels, []Level{DebugLevel, WarnLevel}) {
+		t.Fatalf("Levels should follow SetLevels, got %v", got.Levels)
+	}
+	if !reflect.DeepEqual(got.GlobalFields, []any{"region", "eu"}) {
+		t.Fatalf("GlobalFields should follow SetGlobalFields, got %v", got.GlobalFields)
+	}
+
+	Close()
+	if got := CurrentConfig().FilePath; got != "" {
+		t.Fatalf("FilePath should be empty once the file is closed, got %q", got)
+	}
+}
+
+func TestSnapshot_RestoresFullState(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	defer discardOutput()()
+	var before bytes.Buffer
+	outStdout = &before
+	Init(Config{Levels: []Level{InfoLevel}})
+	defer Init(Config{Levels: AllLevels()})
+
+	restore := Snapshot()
+	outStdout = io.Discard
+	Init(Config{
+		Levels:           AllLevels(),
+		IncludeCallerTag: true,
+		FilePath:         filepath.Join(t.TempDir(), "inside.log"),
+		FieldPrefix:      " | ",
+	})
+	SetGlobalFields("phase", "test")
+	inside := logFile
+	Debugf("inside")
+	restore()
+
+	if isLevelEnabled(DebugLevel) || !isLevelEnabled(InfoLevel) {
+		t.Fatalf("expected only INFO enabled after restore")
+	}
+	if includeCallerTag || globalFields != nil || fieldPrefix != " " {
+		t.Fatalf("expected caller tag, global fields and field prefix restored")
+	}
+	if logFile != nil || fileSink != nil || CurrentConfig().FilePath != "" {
+		t.Fatalf("expected file logging off after restore")
+	}
+	if _, err := inside.WriteString("x"); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected the file opened after Snapshot to be closed, got %v", err)
+	}
+	InfoKV("after", "k", "v")
+	if got := before.String(); got != "after k=v\n" {
+		t.Fatalf("expected output on the original stdout, got %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type traceKey struct{}
+
+// fakeTrace stands in for an OpenTelemetry span context.
+type fakeTrace struct {
+	traceID, spanID string
+}
+
+func fakeExtractor(ctx context.Context) (string, string) {
+	if tr, ok := ctx.Value(traceKey{}).(fakeTrace); ok {
+		return tr.traceID, tr.spanID
+	}
+	return "", ""
+}
+
+func TestInfoCtx_AttachesTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout := outStdout
+	defer func() { outStdout = oldStdout }()
+	outStdout = &buf
+
+	Init(Config{Levels: AllLevels(), TraceExtractor: fakeExtractor})
+	defer func() { traceExtractor = nil }()
+
+	ctx := context.WithValue(context.Background(), traceKey{}, fakeTrace{traceID: "4bf92f35", spanID: "00f067aa"})
+	InfoCtx(ctx, "request handled", "status", 200)
+	DebugCtx(context.WithValue(ctx, traceKey{}, fakeTrace{traceID: "abc"}), "trace only")
+	InfoCtx(context.Background(), "no trace")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"request handled status=200 trace_id=4bf92f35 span_id=00f067aa",
+		"trace only trace_id=abc",
+		"no trace",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got: %q", len(want), buf.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("line %d: expected %q, got %q", i, want[i], lines[i])
+		}
+	}
+}
+
+func TestErrorCtx_NoExtractorByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	oldStderr := outStderr
+	defer func() { outStderr = oldStderr }()
+	outStderr = &buf
+
+	Init(Config{Levels: AllLevels()})
+
+	ctx := context.WithValue(context.Background(), traceKey{}, fakeTrace{traceID: "4bf92f35", spanID: "00f067aa"})
+	ErrorCtx(ctx, "failed")
+	WarnCtx(ctx, "slow")
+
+	if got := buf.String(); got != "failed\nslow\n" {
+		t.Fatalf("expected no trace fields without an extractor, got: %q", got)
+	}
+}
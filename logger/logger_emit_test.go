@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestEmit_DispatchesByLevel(t *testing.T) {
+	var debugBuf, warnBuf, critBuf bytes.Buffer
+	Debug = log.New(&debugBuf, "", 0)
+	Warning = log.New(&warnBuf, "", 0)
+	Crit = log.New(&critBuf, "", 0)
+	SetLevels([]Level{WarnLevel, CritLevel})
+	defer SetLevels(AllLevels())
+	includeCallerTag = true
+	defer func() { includeCallerTag = false }()
+
+	Emit(DebugLevel, "filtered")
+	Emit(WarnLevel, "disk low", "free_mb", 120)
+	Emitf(CritLevel, "replica %d down", 3)
+	Emit(Level(99), "unknown level")
+
+	if debugBuf.Len() != 0 {
+		t.Fatalf("disabled level should be filtered, got: %q", debugBuf.String())
+	}
+	if got := warnBuf.String(); !strings.HasPrefix(got, "[logger.TestEmit_DispatchesByLevel:") || !strings.HasSuffix(got, "] disk low free_mb=120\n") {
+		t.Fatalf("unexpected WARNING line: %q", got)
+	}
+	if got := critBuf.String(); !strings.HasSuffix(got, "] replica 3 down\n") {
+		t.Fatalf("unexpected CRIT line: %q", got)
+	}
+}
+
+func TestEmit_FatalExits(t *testing.T) {
+	codes := captureExit(t)
+	var buf bytes.Buffer
+	Fatal = log.New(&buf, "", 0)
+	enableLevels(FatalLevel)
+
+	Emit(FatalLevel, "shutting down", "reason", "oom")
+	Emitf(FatalLevel, "exit %d", 2)
+
+	if len(*codes) != 2 || (*codes)[0] != fatalExitCode {
+		t.Fatalf("expected two exits with code %d, got %v", fatalExitCode, *codes)
+	}
+	if got := buf.String(); got != "shutting down reason=oom\nexit 2\n" {
+		t.Fatalf("fatal lines should be written before exiting, got: %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInfoEvent_AddsEventFieldAndFormatsMessage(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+
+	Init(Config{Levels: AllLevels(), GlobalFields: []any{"service", "auth"}})
+	defer Init(Config{Levels: AllLevels()})
+
+	InfoEvent("user.login.success", "user %s logged in", "alice")
+	ErrorEvent("user.login.failed", "user %s: %d attempts", "bob", 3)
+
+	want := "user alice logged in event=user.login.success service=auth\n" +
+		"user bob: 3 attempts event=user.login.failed service=auth\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestEvent_FilteredAndJSON(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "events.json")
+
+	Init(Config{Levels: []Level{WarnLevel}, FilePath: logPath, Format: FormatJSON})
+	DebugEvent("cache.miss", "miss for %s", "k1")
+	WarnEvent("disk.low", "disk at %d%%", 91)
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(content, &obj); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %v", content, err)
+	}
+	if obj["event"] != "disk.low" || obj["msg"] != "disk at 91%" {
+		t.Fatalf("unexpected JSON object: %s", content)
+	}
+}
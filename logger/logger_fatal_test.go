@@ -1,10 +1,14 @@
 package logger
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestFatalf_LogsBeforeExit verifies that Fatalf writes the log message before exiting.
@@ -281,3 +285,132 @@ func TestFatal_OutputFormat(t *testing.T) {
 		t.Fatalf("expected caller info in output, got: %q", outputStr)
 	}
 }
+
+// captureExit replaces exitFunc for the duration of a test and returns the recorded codes.
+func captureExit(t *testing.T) *[]int {
+	t.Helper()
+	var codes []int
+	oldExit := exitFunc
+	exitFunc = func(code int) { codes = append(codes, code) }
+	t.Cleanup(func() { exitFunc = oldExit })
+	return &codes
+}
+
+func TestFatalfCode_UsesGivenCode(t *testing.T) {
+	codes := captureExit(t)
+	var buf bytes.Buffer
+	oldStderr := outStderr
+	defer func() { outStderr = oldStderr }()
+	outStderr = &buf
+
+	Init(Config{Levels: AllLevels(), FatalExitCode: 4})
+	FatalfCode(70, "emergency shutdown: %s", "power loss")
+
+	if len(*codes) != 1 || (*codes)[0] != 70 {
+		t.Fatalf("expected exit code 70, got %v", *codes)
+	}
+	if !strings.Contains(buf.String(), "emergency shutdown: power loss") {
+		t.Fatalf("expected fatal message before exit, got: %q", buf.String())
+	}
+}
+
+func TestFatalExitCode_DefaultAndConfigured(t *testing.T) {
+	codes := captureExit(t)
+	defer discardOutput()()
+
+	Init(Config{Levels: AllLevels()})
+	Fatalf("default")
+	Init(Config{Levels: AllLevels(), FatalExitCode: 3})
+	Fatalln("configured")
+	FatalKV("configured kv")
+
+	if len(*codes) != 3 || (*codes)[0] != 1 || (*codes)[1] != 3 || (*codes)[2] != 3 {
+		t.Fatalf("expected exit codes [1 3 3], got %v", *codes)
+	}
+}
+
+func TestOnExit_RunsBeforeExit(t *testing.T) {
+	var order []string
+	oldExit := exitFunc
+	exitFunc = func(code int) { order = append(order, "exit") }
+	defer func() { exitFunc = oldExit }()
+	oldHandlers := exitHandlers
+	defer func() { exitHandlers = oldHandlers }()
+	defer discardOutput()()
+
+	Init(Config{Levels: []Level{InfoLevel}})
+	OnExit(func() { order = append(order, "first") })
+	OnExit(func() { order = append(order, "second") })
+	FatalfCode(2, "filtered but still exits")
+
+	if strings.Join(order, ",") != "first,second,exit" {
+		t.Fatalf("expected handlers before exit, got %v", order)
+	}
+}
+
+func TestFatalf_FlushesBufferedFile(t *testing.T) {
+	captureExit(t)
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "fatal.log")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, FlushInterval: time.Hour})
+	defer Close()
+
+	oldHandlers := exitHandlers
+	defer func() { exitHandlers = oldHandlers }()
+	var seen string
+	OnExit(func() {
+		content, _ := os.ReadFile(logPath)
+		seen = string(content)
+	})
+
+	Fatalf("going down")
+
+	if !strings.Contains(seen, "going down") {
+		t.Fatalf("fatal line should be flushed before exit handlers run, got: %q", seen)
+	}
+}
+
+func TestFailFastLevel_ErrorExitsAfterWriting(t *testing.T) {
+	var stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stderrBuf
+	outStderr = &stderrBuf
+
+	var order []string
+	oldExit := exitFunc
+	exitFunc = func(code int) { order = append(order, fmt.Sprintf("exit %d", code)) }
+	defer func() { exitFunc = oldExit }()
+	oldHandlers := exitHandlers
+	defer func() { exitHandlers = oldHandlers }()
+	OnExit(func() {
+		order = append(order, "handler saw line="+fmt.Sprint(strings.Contains(stderrBuf.String(), "broken invariant")))
+	})
+
+	Init(Config{Levels: AllLevels(), FailFastLevel: ErrorLevel})
+	defer Init(Config{Levels: AllLevels()})
+
+	Warnf("below threshold")
+	if len(order) != 0 {
+		t.Fatalf("WARNING should not trigger fail-fast, got %v", order)
+	}
+	Errorf("broken invariant")
+
+	if strings.Join(order, ",") != "handler saw line=true,exit 1" {
+		t.Fatalf("expected exit after the line was written, got %v", order)
+	}
+}
+
+func TestFailFastLevel_OffByDefault(t *testing.T) {
+	codes := captureExit(t)
+	defer discardOutput()()
+
+	Init(Config{Levels: AllLevels()})
+	Errorf("just an error")
+	Emergf("still running")
+
+	if len(*codes) != 0 {
+		t.Fatalf("fail-fast should be off by default, got exits %v", *codes)
+	}
+}
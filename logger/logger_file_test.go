@@ -1,12 +1,16 @@
 package logger
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func discardOutput() func() {
@@ -19,6 +23,14 @@ func discardOutput() func() {
 	}
 }
 
+// setNow freezes nowFunc at ts for the rest of the test.
+func setNow(t *testing.T, ts time.Time) {
+	t.Helper()
+	prev := nowFunc
+	nowFunc = func() time.Time { return ts }
+	t.Cleanup(func() { nowFunc = prev })
+}
+
 func TestFileLogging_ColorizedStripsAnsi(t *testing.T) {
 	defer discardOutput()()
 	// Create a temporary log file
@@ -308,3 +320,348 @@ func TestInit_DefaultConfig(t *testing.T) {
 		t.Errorf("Close() should not error, got: %v", err)
 	}
 }
+
+func TestFileLogging_CSVEscaping(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.csv")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, Format: FormatCSV})
+	Infof(`saved "report", 3 rows`)
+	WarnKV("slow query", "table", "users,orders", "ms", 250)
+	Close()
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+	defer f.Close()
+
+	raw, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(raw), `"saved ""report"", 3 rows"`) {
+		t.Fatalf("expected quotes doubled and field quoted, got: %q", raw)
+	}
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("file should be valid CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header plus 2 rows, got %d: %q", len(rows), rows)
+	}
+	if strings.Join(rows[0], ",") != "timestamp,level,caller,message,fields" {
+		t.Fatalf("unexpected header row: %q", rows[0])
+	}
+	if rows[1][1] != "INFO" || rows[1][3] != `saved "report", 3 rows` {
+		t.Fatalf("unexpected info row: %q", rows[1])
+	}
+	if rows[2][1] != "WARNING" || rows[2][3] != "slow query" || rows[2][4] != "table=users,orders ms=250" {
+		t.Fatalf("unexpected warning row: %q", rows[2])
+	}
+}
+
+func TestFileLogging_CSVHeaderOnlyForEmptyFile(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "append.csv")
+
+	Init(Config{Levels: []Level{InfoLevel}, FilePath: logPath, Format: FormatCSV})
+	Infof("first")
+	Close()
+
+	Init(Config{Levels: []Level{InfoLevel}, FilePath: logPath, Format: FormatCSV})
+	Infof("second")
+	Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if n := strings.Count(string(content), "timestamp,level,caller,message,fields"); n != 1 {
+		t.Fatalf("expected exactly one header row, got %d: %q", n, content)
+	}
+}
+
+func TestFileLogging_TSV(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.tsv")
+
+	Init(Config{Levels: []Level{InfoLevel}, FilePath: logPath, Format: FormatTSV})
+	Infof("tab separated")
+	Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || lines[0] != "timestamp\tlevel\tcaller\tmessage\tfields" {
+		t.Fatalf("expected TSV header and one row, got: %q", content)
+	}
+	if !strings.HasSuffix(lines[1], "\tINFO\t\ttab separated") {
+		t.Fatalf("unexpected TSV row: %q", lines[1])
+	}
+}
+
+func TestCSVSink_RecoversFromFailedWrite(t *testing.T) {
+	var buf bytes.Buffer
+	out := &flakyWriter{w: &buf}
+	sink := newCSVSink(out, ',', false, false)
+
+	out.fails = 1
+	if err := sink.WriteRecord(Record{Level: InfoLevel, Message: "lost"}); err == nil {
+		t.Fatal("expected the failed write to be reported")
+	}
+	for _, msg := range []string{"first", "second"} {
+		if err := sink.WriteRecord(Record{Level: InfoLevel, Message: msg}); err != nil {
+			t.Fatalf("write after a transient failure should succeed: %v", err)
+		}
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output should be valid CSV: %v", err)
+	}
+	if len(rows) != 2 || rows[0][3] != "first" || rows[1][3] != "second" {
+		t.Fatalf("expected the rows after the failure, got %q", rows)
+	}
+}
+
+func TestNowFunc_FrozenTimestamps(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = io.Discard
+	setNow(t, time.Date(2024, 3, 9, 14, 5, 6, 789000000, time.Local))
+
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "app.log")
+	Init(Config{Levels: AllLevels(), Colorize: true, FilePath: textPath})
+	Infof("frozen")
+	Close()
+
+	if got := ansiEscape.ReplaceAllString(stdoutBuf.String(), ""); got != "2024/03/09 14:05:06 frozen\n" {
+		t.Fatalf("unexpected console line: %q", got)
+	}
+	content, err := os.ReadFile(textPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if string(content) != "2024/03/09 14:05:06 frozen\n" {
+		t.Fatalf("unexpected file line: %q", content)
+	}
+
+	jsonPath := filepath.Join(dir, "app.json")
+	Init(Config{Levels: AllLevels(), FilePath: jsonPath, Format: FormatJSON})
+	Infof("frozen")
+	Close()
+
+	content, err = os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	want := `{"time":"` + nowFunc().Format(time.RFC3339Nano) + `","level":"INFO","msg":"frozen"}` + "\n"
+	if string(content) != want {
+		t.Fatalf("expected %q, got %q", want, content)
+	}
+}
+
+func TestFlushInterval_LinesAppearAfterFlush(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "buffered.log")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, FlushInterval: 30 * time.Millisecond})
+	defer Close()
+
+	Infof("buffered line")
+
+	read := func() string {
+		content, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatalf("failed to read log file: %v", err)
+		}
+		return string(content)
+	}
+	if got := read(); got != "" {
+		t.Fatalf("line should stay buffered until the flush, got: %q", got)
+	}
+	waitFor(t, func() bool { return strings.Contains(read(), "buffered line") }, "line was not flushed")
+}
+
+func TestFlushInterval_CloseFlushesPending(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "buffered.log")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, FlushInterval: time.Hour, Format: FormatJSON})
+	Infof("pending line")
+	if err := Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), `"msg":"pending line"`) {
+		t.Fatalf("Close should flush pending bytes, got: %q", content)
+	}
+}
+
+func TestFlushInterval_RecoversFromFailedFlush(t *testing.T) {
+	defer Snapshot()()
+	var buf bytes.Buffer
+	out := &flakyWriter{w: &buf}
+	var reported []error
+	onWriteError = func(err error) { reported = append(reported, err) }
+	fileBuffer, fileBufferOut = bufio.NewWriterSize(out, 16), out
+
+	out.fails = 1
+	fileBufferWriter{}.Write([]byte("lost\n"))
+	flushFileBuffer()
+	if len(reported) != 1 || !strings.Contains(reported[0].Error(), "5 buffered bytes discarded") {
+		t.Fatalf("expected the failed flush to be reported, got %v", reported)
+	}
+
+	out.fails = 1
+	if _, err := (fileBufferWriter{}).Write([]byte("longer than the buffer\n")); err == nil {
+		t.Fatal("expected the failed write to be reported")
+	}
+
+	fileBufferWriter{}.Write([]byte("kept\n"))
+	flushFileBuffer()
+	if len(reported) != 1 || buf.String() != "kept\n" {
+		t.Fatalf("lines after a failure should be written, got %q (errors %v)", buf.String(), reported)
+	}
+}
+
+func TestHeader_WrittenForNewFileOnly(t *testing.T) {
+	defer discardOutput()()
+	setNow(t, time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	config := Config{
+		Levels:   []Level{InfoLevel, ErrorLevel},
+		FilePath: logPath,
+		Header:   []any{"version", "1.4.2"},
+	}
+
+	Init(config)
+	Infof("first run")
+	Close()
+	Init(config)
+	Infof("second run")
+	Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a 3-line header and 2 log lines, got: %q", content)
+	}
+	if lines[0] != "# ---- log started 2024-03-09T14:05:06Z ----" || lines[2] != "# ----" {
+		t.Fatalf("unexpected header delimiters: %q", lines[:3])
+	}
+	if !strings.HasPrefix(lines[1], "# host=") || !strings.HasSuffix(lines[1], " levels=INFO,ERROR version=1.4.2") {
+		t.Fatalf("unexpected header fields: %q", lines[1])
+	}
+	if strings.Count(string(content), "log started") != 1 {
+		t.Fatalf("header should not repeat when appending, got: %q", content)
+	}
+}
+
+func TestSeverityMarkers_WrapCritInFileOnly(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	defer discardOutput()()
+	var console bytes.Buffer
+	outStderr = &console
+	setNow(t, time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+	logPath := filepath.Join(t.TempDir(), "app.log")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, IncludeLevelPrefix: true, SeverityMarkers: true})
+	Infof("routine")
+	Critf("disk failing")
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	want := "2024/03/09 14:05:06 [INFO] routine\n" +
+		">>> CRIT 2024/03/09 14:05:06 [CRIT] disk failing <<<\n"
+	if string(content) != want {
+		t.Fatalf("expected %q, got %q", want, content)
+	}
+	if strings.Contains(console.String(), ">>>") {
+		t.Fatalf("console should not carry markers, got %q", console.String())
+	}
+}
+
+func TestFileFieldDenylist_DropsKeysFromFileOnly(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = io.Discard
+	dir := t.TempDir()
+	sink := &recordingSink{}
+
+	for _, format := range []Format{FormatText, FormatJSON} {
+		stdoutBuf.Reset()
+		logPath := filepath.Join(dir, "app-"+format.String())
+		Init(Config{
+			Levels:            AllLevels(),
+			FilePath:          logPath,
+			Format:            format,
+			FileFieldDenylist: []string{"internal_id"},
+			Sinks:             []Sink{sink},
+		})
+		InfoKV("order placed", "order", 42, "internal_id", "shard-7/abc")
+		Close()
+
+		if got := stdoutBuf.String(); got != "order placed order=42 internal_id=shard-7/abc\n" {
+			t.Fatalf("%s: console should keep every field, got: %q", format, got)
+		}
+		content, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatalf("failed to read log file: %v", err)
+		}
+		if strings.Contains(string(content), "internal_id") || !strings.Contains(string(content), "order") {
+			t.Fatalf("%s: file should drop the denied key, got: %q", format, content)
+		}
+	}
+	if fields := sink.records[0].Fields; len(fields) != 2 {
+		t.Fatalf("sinks should keep every field, got %+v", fields)
+	}
+}
+
+func TestSummaryOnClose_LogsPerLevelCounts(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+	logPath := filepath.Join(t.TempDir(), "batch.log")
+
+	Init(Config{Levels: []Level{InfoLevel, NoticeLevel, WarnLevel, ErrorLevel}, FilePath: logPath, SummaryOnClose: true, IncludeCallerTag: true})
+	defer Init(Config{Levels: AllLevels()})
+	Debugf("filtered, not counted")
+	for i := 0; i < 3; i++ {
+		Infof("row %d", i)
+	}
+	Warnf("slow")
+	ErrorKV("bad row", "id", 7)
+	buf.Reset()
+	Close()
+
+	if got := buf.String(); !strings.HasSuffix(got, "log summary info=3 warning=1 error=1\n") ||
+		!strings.Contains(got, "TestSummaryOnClose_LogsPerLevelCounts") {
+		t.Fatalf("unexpected summary line: %q", got)
+	}
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "log summary info=3 warning=1 error=1\n") {
+		t.Fatalf("summary should reach the file, got: %q", content)
+	}
+}
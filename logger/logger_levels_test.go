@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTemporaryLevels_RevertsAfterDuration(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &syncBuffer{buf: &buf}
+	outStderr = io.Discard
+
+	Init(Config{Levels: []Level{InfoLevel, NoticeLevel}})
+	TemporaryLevels([]Level{DebugLevel, InfoLevel, NoticeLevel}, 20*time.Millisecond)
+
+	if !isLevelEnabled(DebugLevel) {
+		t.Fatal("debug should be enabled while elevated")
+	}
+	waitFor(t, func() bool { return !isLevelEnabled(DebugLevel) }, "debug was not reverted")
+
+	if !isLevelEnabled(InfoLevel) || !isLevelEnabled(NoticeLevel) {
+		t.Fatal("original levels should be restored")
+	}
+	out := buf.String()
+	if !strings.Contains(out, "levels temporarily set to DEBUG,INFO,NOTICE for 20ms") {
+		t.Fatalf("expected elevation notice, got: %q", out)
+	}
+	if !strings.Contains(out, "levels restored to INFO,NOTICE") {
+		t.Fatalf("expected restore notice, got: %q", out)
+	}
+}
+
+func TestTemporaryLevels_RepeatResetsTimer(t *testing.T) {
+	defer discardOutput()()
+
+	Init(Config{Levels: []Level{ErrorLevel}})
+	TemporaryLevels([]Level{InfoLevel}, 20*time.Millisecond)
+	cancel := TemporaryLevels([]Level{DebugLevel}, time.Hour)
+
+	time.Sleep(50 * time.Millisecond)
+	if !isLevelEnabled(DebugLevel) || isLevelEnabled(ErrorLevel) {
+		t.Fatal("second call should replace the levels and restart the timer")
+	}
+
+	cancel()
+	if isLevelEnabled(DebugLevel) || !isLevelEnabled(ErrorLevel) {
+		t.Fatal("cancel should restore the levels from before the first call")
+	}
+	cancel()
+}
+
+func TestSetLevels_ConcurrentWithLogging(t *testing.T) {
+	defer discardOutput()()
+	Init(Config{Levels: AllLevels()})
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					Debugf("debug")
+					Infof("info")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		if i%2 == 0 {
+			SetLevels([]Level{InfoLevel})
+		} else {
+			SetLevels(AllLevels())
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	SetLevels([]Level{ErrorLevel, FatalLevel})
+	if isLevelEnabled(InfoLevel) || !isLevelEnabled(ErrorLevel) || !isLevelEnabled(FatalLevel) {
+		t.Fatalf("unexpected enabled levels after SetLevels: %v", currentLevels())
+	}
+}
+
+func TestLevelMask_IgnoresOutOfRangeLevels(t *testing.T) {
+	m := maskOf(map[Level]bool{Level(-1): true, Level(40): true, InfoLevel: true})
+	if !m.has(InfoLevel) || m.has(Level(-1)) || m.has(Level(40)) || m.has(DebugLevel) {
+		t.Fatalf("unexpected mask %b", m)
+	}
+}
+
+func TestDisable_MutesAndRestoresLevels(t *testing.T) {
+	defer discardOutput()()
+	defer Snapshot()()
+	codes := captureExit(t)
+	collector := &recordingSink{}
+	Init(Config{Levels: []Level{InfoLevel, WarnLevel, FatalLevel}, Sinks: []Sink{collector}})
+
+	Infof("one")
+	Disable()
+	if Enabled() {
+		t.Fatalf("expected Enabled to report false after Disable")
+	}
+	Infof("muted")
+	WarnKV("muted")
+	Fatalf("muted fatal")
+	Enable()
+	Infof("two")
+	Debugf("still filtered")
+	Warnf("three")
+
+	if len(collector.records) != 3 {
+		t.Fatalf("expected 3 lines outside the disabled section, got %+v", collector.records)
+	}
+	for i, want := range []string{"one", "two", "three"} {
+		if got := collector.records[i].Message; got != want {
+			t.Fatalf("record %d: expected %q, got %q", i, want, got)
+		}
+	}
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Fatalf("Fatalf should still exit while disabled, got %v", *codes)
+	}
+	if got := CurrentConfig().Levels; len(got) != 3 {
+		t.Fatalf("expected the configured levels to be untouched, got %v", got)
+	}
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	panPattern   = regexp.MustCompile(`\b\d{12}(\d{4})\b`)
+	emailPattern = regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`)
+)
+
+func TestRedactPatterns_MasksMessagesAndFields(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout := outStdout
+	defer func() { outStdout = oldStdout }()
+	outStdout = &buf
+
+	Init(Config{Levels: AllLevels(), RedactPatterns: []*regexp.Regexp{panPattern, emailPattern}})
+	defer Init(Config{Levels: AllLevels()})
+
+	Infof("charged card 4111111111111111 for alice@example.com")
+	InfoKV("payment", "pan", int64(4111111111111111), "email", "bob@example.org", "amount", 42)
+
+	want := "charged card ***1111 for ***\n" +
+		"payment pan=***1111 email=*** amount=42\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected redaction:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestRedactPatterns_FileAndSinksSeeRedactedRecord(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.json")
+	collector := &recordingSink{}
+
+	Init(Config{
+		Levels:         AllLevels(),
+		FilePath:       logPath,
+		Format:         FormatJSON,
+		Sinks:          []Sink{collector},
+		RedactPatterns: []*regexp.Regexp{emailPattern},
+	})
+	InfoKV("signup", "email", "carol@example.net", "plan", "pro")
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if strings.Contains(string(content), "carol@") || !strings.Contains(string(content), `"email":"***","plan":"pro"`) {
+		t.Fatalf("file should be redacted, got: %s", content)
+	}
+	if got := collector.records[0].Fields[0].Value; got != "***" {
+		t.Fatalf("sinks should receive the redacted value, got %v", got)
+	}
+}
+
+func TestRedactPatterns_AuditStaysVerbatim(t *testing.T) {
+	defer discardOutput()()
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+
+	Init(Config{Levels: AllLevels(), AuditFilePath: auditPath, RedactPatterns: []*regexp.Regexp{emailPattern}})
+	Audit("role granted", "user", "dave@example.com")
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	content, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("failed to read audit file: %v", err)
+	}
+	if !strings.Contains(string(content), "user=dave@example.com") {
+		t.Fatalf("audit lines should not be redacted, got: %q", content)
+	}
+}
+
+func TestRedactPatterns_SurviveWatchConfigReload(t *testing.T) {
+	defer discardOutput()()
+	defer Snapshot()()
+	dir := t.TempDir()
+	oldPath, newPath := filepath.Join(dir, "old.log"), filepath.Join(dir, "new.log")
+	configPath := filepath.Join(dir, "logger.json")
+	if err := os.WriteFile(configPath, []byte(`{"file_path": "`+filepath.ToSlash(oldPath)+`"}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	Init(Config{Levels: AllLevels(), FilePath: oldPath, RedactPatterns: []*regexp.Regexp{panPattern}})
+
+	stop, err := WatchConfig(configPath, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WatchConfig failed: %v", err)
+	}
+	defer stop()
+	if err := os.WriteFile(configPath, []byte(`{"file_path": "`+filepath.ToSlash(newPath)+`"}`), 0644); err != nil {
+		t.Fatalf("failed to rewrite config: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(configPath, future, future); err != nil {
+		t.Fatalf("failed to bump mtime: %v", err)
+	}
+	waitFor(t, func() bool {
+		logMutex.Lock()
+		defer logMutex.Unlock()
+		return logFilePath == newPath
+	}, "config reload did not switch the log file")
+	stop()
+
+	Infof("charged card 4111111111111111")
+	content, err := os.ReadFile(newPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "charged card ***1111") {
+		t.Fatalf("redaction should survive the reload, got: %q", content)
+	}
+}
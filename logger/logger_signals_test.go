@@ -0,0 +1,57 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSignals_ClosesFileAndKeepsAppHandler(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "signal.log")
+	Init(Config{Levels: []Level{InfoLevel}, FilePath: logPath})
+	defer Close()
+
+	appCh := make(chan os.Signal, 1)
+	signal.Notify(appCh, syscall.SIGUSR1)
+	defer signal.Stop(appCh)
+
+	stop := HandleSignals(syscall.SIGUSR1)
+	defer stop()
+
+	Infof("before signal")
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("failed to send signal: %v", err)
+	}
+
+	select {
+	case <-appCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("application handler did not receive the signal")
+	}
+	waitFor(t, func() bool {
+		logMutex.Lock()
+		defer logMutex.Unlock()
+		return logFile == nil
+	}, "log file was not closed after the signal")
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "before signal") {
+		t.Fatalf("expected flushed line in file, got: %q", content)
+	}
+}
+
+func TestHandleSignals_StopUninstalls(t *testing.T) {
+	stop := HandleSignals(syscall.SIGUSR2)
+	stop()
+	stop()
+}
@@ -0,0 +1,315 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordingSink keeps every record it receives.
+type recordingSink struct {
+	records []Record
+}
+
+func (s *recordingSink) WriteRecord(rec Record) error {
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func TestSinks_ReceiveSameRecordAlongsideFile(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	journal, collector := &recordingSink{}, &recordingSink{}
+
+	Init(Config{
+		Levels:           AllLevels(),
+		FilePath:         logPath,
+		IncludeCallerTag: true,
+		Sinks:            []Sink{journal, collector},
+	})
+	defer Close()
+
+	InfoKV("user login", "user", "alice")
+
+	if len(journal.records) != 1 || len(collector.records) != 1 {
+		t.Fatalf("expected one record per sink, got %d and %d", len(journal.records), len(collector.records))
+	}
+	a, b := journal.records[0], collector.records[0]
+	if a.Message != "user login" || a.Level != InfoLevel || !strings.Contains(a.Caller, "TestSinks_ReceiveSameRecordAlongsideFile") {
+		t.Fatalf("unexpected record: %+v", a)
+	}
+	if !a.Time.Equal(b.Time) || a.Message != b.Message || a.Caller != b.Caller ||
+		len(b.Fields) != 1 || b.Fields[0] != (Field{Key: "user", Value: "alice"}) {
+		t.Fatalf("sinks should receive the same record, got %+v and %+v", a, b)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "user login user=alice") {
+		t.Fatalf("file should still receive the text line, got: %q", content)
+	}
+}
+
+func TestSinks_ErrorReportedWithoutBlockingOthers(t *testing.T) {
+	defer discardOutput()()
+	healthy := &recordingSink{}
+	var reported error
+
+	Init(Config{
+		Levels:       AllLevels(),
+		Sinks:        []Sink{failingSink{}, healthy},
+		OnWriteError: func(err error) { reported = err },
+	})
+
+	Errorf("still delivered")
+
+	if len(healthy.records) != 1 {
+		t.Fatalf("healthy sink should receive the record, got %d", len(healthy.records))
+	}
+	if reported == nil || reported.Error() != "collector down" {
+		t.Fatalf("sink error should be reported, got %v", reported)
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) WriteRecord(Record) error { return errors.New("collector down") }
+
+func TestSinkFunc_CollectsFilteredRecords(t *testing.T) {
+	defer discardOutput()()
+	var records []Record
+	extra := &recordingSink{}
+
+	Init(Config{
+		Levels:   []Level{InfoLevel, ErrorLevel},
+		Sinks:    []Sink{extra},
+		SinkFunc: func(rec Record) { records = append(records, rec) },
+	})
+	defer Init(Config{Levels: AllLevels()})
+
+	Debugf("filtered")
+	InfoKV("shipped", "topic", "orders")
+	Errorf("also shipped")
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d: %+v", len(records), records)
+	}
+	if records[0].Message != "shipped" || records[0].Level != InfoLevel ||
+		len(records[0].Fields) != 1 || records[0].Fields[0] != (Field{Key: "topic", Value: "orders"}) {
+		t.Fatalf("unexpected record: %+v", records[0])
+	}
+	if records[1].Message != "also shipped" || records[1].Level != ErrorLevel {
+		t.Fatalf("unexpected record: %+v", records[1])
+	}
+	if len(extra.records) != 2 {
+		t.Fatalf("Sinks should still receive records, got %d", len(extra.records))
+	}
+}
+
+func TestSinkFunc_PanicIsReported(t *testing.T) {
+	defer discardOutput()()
+	var reported error
+
+	Init(Config{
+		Levels:       AllLevels(),
+		SinkFunc:     func(Record) { panic("broker unreachable") },
+		OnWriteError: func(err error) { reported = err },
+	})
+	defer Init(Config{Levels: AllLevels()})
+
+	Infof("survives")
+
+	if reported == nil || !strings.Contains(reported.Error(), "broker unreachable") {
+		t.Fatalf("panic should be reported as a write error, got %v", reported)
+	}
+}
+
+func TestFileLogging_JSON(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.json")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, Format: FormatJSON})
+	InfoKV("request <done>", "status", 200, "err", errors.New("timeout"), "body", []byte("ok"))
+	Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	line := strings.TrimSpace(string(content))
+	if strings.Count(line, "\n") != 0 {
+		t.Fatalf("expected one JSON line, got: %q", content)
+	}
+	if !strings.Contains(line, `"level":"INFO","msg":"request <done>","status":200,"err":"timeout","body":"ok"}`) {
+		t.Fatalf("unexpected key order or values: %s", line)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		t.Fatalf("line should be valid JSON: %v", err)
+	}
+	if _, ok := obj["caller"]; ok {
+		t.Fatalf("caller should be omitted when caller tagging is off: %s", line)
+	}
+}
+
+func TestFileLogging_JSONCallerFields(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.json")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, Format: FormatJSON, IncludeCallerTag: true})
+	Infof("tagged")
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(content, &obj); err != nil {
+		t.Fatalf("invalid JSON %q: %v", content, err)
+	}
+	if fn, ok := obj["caller_func"].(string); !ok || fn != "logger.TestFileLogging_JSONCallerFields" {
+		t.Fatalf("caller_func should be the calling function, got %v", obj["caller_func"])
+	}
+	if file, ok := obj["caller_file"].(string); !ok || filepath.Base(file) != "logger_sink_test.go" {
+		t.Fatalf("caller_file should be this file, got %v", obj["caller_file"])
+	}
+	if line, ok := obj["caller_line"].(float64); !ok || line <= 0 {
+		t.Fatalf("caller_line should be a positive number, got %v", obj["caller_line"])
+	}
+	if _, ok := obj["caller"]; ok {
+		t.Fatalf("the caller tag should not be duplicated: %s", content)
+	}
+	if obj["msg"] != "tagged" {
+		t.Fatalf("message should stay clean, got %v", obj["msg"])
+	}
+}
+
+func TestFileLogging_JSONProcessFieldsAreTopLevel(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.json")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, Format: FormatJSON, IncludePID: true})
+	defer func() { processFields = nil }()
+	Infof("started")
+	Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(content, &obj); err != nil {
+		t.Fatalf("line should be valid JSON: %v", err)
+	}
+	if pid, ok := obj["pid"].(float64); !ok || int(pid) != os.Getpid() {
+		t.Fatalf("expected top-level pid, got: %s", content)
+	}
+}
+
+func TestApi_StatusRenderedPerFormat(t *testing.T) {
+	defer discardOutput()()
+	dir := t.TempDir()
+	textPath, jsonPath := filepath.Join(dir, "api.log"), filepath.Join(dir, "api.json")
+	collector := &recordingSink{}
+
+	Init(Config{Levels: AllLevels(), FilePath: textPath, Sinks: []Sink{collector}})
+	Api(404, "not found")
+	Close()
+	Init(Config{Levels: AllLevels(), FilePath: jsonPath, Format: FormatJSON})
+	Api(200, "ok")
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	text, err := os.ReadFile(textPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.HasSuffix(string(text), " [404] not found\n") {
+		t.Fatalf("text output should keep the [status] prefix, got: %q", text)
+	}
+	if rec := collector.records[0]; rec.Status != 404 || rec.Message != "not found" || rec.Level != WarnLevel {
+		t.Fatalf("record should carry the status separately, got %+v", rec)
+	}
+
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(content, &obj); err != nil {
+		t.Fatalf("invalid JSON %q: %v", content, err)
+	}
+	if obj["status"] != float64(200) || obj["msg"] != "ok" {
+		t.Fatalf(`expected "status":200 and a clean "msg", got: %s`, content)
+	}
+}
+
+func TestApiLevel_OverridesStatusLevel(t *testing.T) {
+	defer discardOutput()()
+	collector := &recordingSink{}
+	Init(Config{Levels: []Level{InfoLevel, WarnLevel}, Sinks: []Sink{collector}})
+	defer Init(Config{Levels: AllLevels()})
+
+	ApiLevel(404, InfoLevel, "cache miss")
+	ApiLevel(500, DebugLevel, "filtered out")
+
+	if len(collector.records) != 1 {
+		t.Fatalf("expected only the INFO line to pass filtering, got %+v", collector.records)
+	}
+	if rec := collector.records[0]; rec.Level != InfoLevel || rec.Status != 404 || rec.Message != "cache miss" {
+		t.Fatalf("expected overridden INFO level with status 404, got %+v", rec)
+	}
+}
+
+func TestApiLevel_FatalExitsAndUnknownLevelIgnored(t *testing.T) {
+	defer discardOutput()()
+	codes := captureExit(t)
+	collector := &recordingSink{}
+	Init(Config{Levels: AllLevels(), Sinks: []Sink{collector}})
+	defer Init(Config{Levels: AllLevels()})
+
+	ApiLevel(500, Level(42), "out of range")
+	if len(collector.records) != 0 || len(*codes) != 0 {
+		t.Fatalf("unknown level should be ignored, got records %+v exits %v", collector.records, *codes)
+	}
+
+	ApiLevel(500, FatalLevel, "backend gone")
+	if len(collector.records) != 1 || collector.records[0].Level != FatalLevel {
+		t.Fatalf("expected one FATAL record, got %+v", collector.records)
+	}
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Fatalf("FatalLevel should exit with code 1, got %v", *codes)
+	}
+}
+
+func TestUnits_HumanizedOnConsoleRawInJSON(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	defer discardOutput()()
+	var console bytes.Buffer
+	outStdout = &console
+	jsonPath := filepath.Join(t.TempDir(), "units.json")
+
+	Init(Config{Levels: AllLevels(), FilePath: jsonPath, Format: FormatJSON})
+	InfoKV("done", "size", Bytes(1048576), "rows", Count(1500), "small", Bytes(512))
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	if got := console.String(); !strings.HasSuffix(got, "done size=1.0MiB rows=1.5k small=512B\n") {
+		t.Fatalf("expected humanized console fields, got: %q", got)
+	}
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), `"size":1048576,"rows":1500,"small":512`) {
+		t.Fatalf("expected raw integers in JSON, got: %s", content)
+	}
+}
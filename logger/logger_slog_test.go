@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSetLeveler_FollowsLevelVar(t *testing.T) {
+	var buf bytes.Buffer
+	Debug = log.New(&buf, "", 0)
+	Info = log.New(&buf, "", 0)
+	Notice = log.New(&buf, "", 0)
+	Warning = log.New(&buf, "", 0)
+
+	var lv slog.LevelVar
+	lv.Set(slog.LevelWarn)
+	SetLeveler(&lv)
+	defer SetLeveler(nil)
+
+	Debugf("debug-hidden")
+	Infof("info-hidden")
+	Noticef("notice-hidden")
+	Warnf("warn-shown")
+
+	lv.Set(slog.LevelInfo)
+	Infof("info-shown")
+	Noticef("notice-shown")
+
+	out := buf.String()
+	for _, hidden := range []string{"debug-hidden", "info-hidden", "notice-hidden"} {
+		if strings.Contains(out, hidden) {
+			t.Fatalf("expected %q to be filtered, got: %q", hidden, out)
+		}
+	}
+	for _, shown := range []string{"warn-shown", "info-shown", "notice-shown"} {
+		if !strings.Contains(out, shown) {
+			t.Fatalf("expected %q in output, got: %q", shown, out)
+		}
+	}
+}
+
+func TestSetLeveler_NilRestoresStaticLevels(t *testing.T) {
+	var buf bytes.Buffer
+	Debug = log.New(&buf, "", 0)
+	SetLevels([]Level{DebugLevel})
+
+	SetLeveler(slog.LevelError)
+	Debugf("filtered-by-leveler")
+	SetLeveler(nil)
+	Debugf("allowed-by-map")
+
+	out := buf.String()
+	if strings.Contains(out, "filtered-by-leveler") {
+		t.Fatalf("leveler should filter debug, got: %q", out)
+	}
+	if !strings.Contains(out, "allowed-by-map") {
+		t.Fatalf("static levels should apply after SetLeveler(nil), got: %q", out)
+	}
+}
+
+func TestSlogLevel_PreservesSeverityOrdering(t *testing.T) {
+	levels := AllLevels()
+	for i := 1; i < len(levels); i++ {
+		if slogLevel(levels[i-1]) >= slogLevel(levels[i]) {
+			t.Fatalf("slogLevel(%d)=%d should be below slogLevel(%d)=%d",
+				levels[i-1], slogLevel(levels[i-1]), levels[i], slogLevel(levels[i]))
+		}
+	}
+	if slogLevel(ErrorLevel) != slog.LevelError || slogLevel(DebugLevel) != slog.LevelDebug {
+		t.Fatalf("standard levels should match slog, got error=%d debug=%d",
+			slogLevel(ErrorLevel), slogLevel(DebugLevel))
+	}
+}
+
+// secret is a slog.LogValuer that hides its contents.
+type secret string
+
+func (secret) LogValue() slog.Value { return slog.StringValue("REDACTED") }
+
+func TestInfoAttrs_RendersAttrsGroupsAndLogValuers(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+
+	InfoAttrs("request",
+		slog.String("path", "/users"),
+		slog.Int("n", 3),
+		slog.Group("resp", slog.Int("status", 200), slog.Group("cache", slog.Bool("hit", false))),
+		slog.Group("", slog.String("inlined", "yes")),
+		slog.Any("token", secret("hunter2")),
+		slog.Group("empty"),
+		slog.Attr{},
+	)
+
+	want := "request path=/users n=3 resp.status=200 resp.cache.hit=false inlined=yes token=REDACTED\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestDebugAttrs_FilteredByLevel(t *testing.T) {
+	var buf bytes.Buffer
+	Debug = log.New(&buf, "", 0)
+	SetLevels([]Level{InfoLevel})
+	defer SetLevels(AllLevels())
+
+	DebugAttrs("hidden", slog.String("k", "v"))
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected no output while DEBUG is disabled, got %q", buf.String())
+	}
+}
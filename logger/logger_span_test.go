@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var durationField = regexp.MustCompile(`duration_ms=(-?\d+)`)
+
+func spanDuration(t *testing.T, line string) int64 {
+	t.Helper()
+	m := durationField.FindStringSubmatch(line)
+	if m == nil {
+		t.Fatalf("expected duration_ms field, got: %q", line)
+	}
+	ms, _ := strconv.ParseInt(m[1], 10, 64)
+	return ms
+}
+
+func TestSpan_EndLogsDurationAtInfo(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	SetLevels([]Level{InfoLevel})
+	includeCallerTag = true
+	defer func() { includeCallerTag = false }()
+
+	span := StartSpan("load cache")
+	time.Sleep(5 * time.Millisecond)
+	span.End("entries", 3)
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "[logger.TestSpan_EndLogsDurationAtInfo:") {
+		t.Fatalf("caller tag should point at End, got: %q", out)
+	}
+	if !strings.Contains(out, "load cache duration_ms=") || !strings.HasSuffix(out, " entries=3\n") {
+		t.Fatalf("unexpected span line: %q", out)
+	}
+	if ms := spanDuration(t, out); ms < 5 {
+		t.Fatalf("expected at least 5ms, got %d", ms)
+	}
+}
+
+func TestSpan_EndErrLevels(t *testing.T) {
+	var infoBuf, errBuf bytes.Buffer
+	Info = log.New(&infoBuf, "", 0)
+	Error = log.New(&errBuf, "", 0)
+	SetLevels([]Level{InfoLevel, ErrorLevel})
+
+	StartSpan("ok op").EndErr(nil, "rows", 1)
+	StartSpan("bad op").EndErr(errors.New("conn reset"))
+
+	if out := infoBuf.String(); !strings.Contains(out, "ok op duration_ms=") || !strings.Contains(out, "rows=1") {
+		t.Fatalf("nil error should log at INFO, got: %q", out)
+	}
+	out := errBuf.String()
+	if !strings.Contains(out, "bad op duration_ms=") || !strings.Contains(out, "error=conn reset") {
+		t.Fatalf("non-nil error should log at ERROR, got: %q", out)
+	}
+	if ms := spanDuration(t, out); ms < 0 {
+		t.Fatalf("duration should be non-negative, got %d", ms)
+	}
+}
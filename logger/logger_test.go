@@ -2,17 +2,32 @@ package logger
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
+// enableLevels adds levels to the currently enabled set.
+func enableLevels(levels ...Level) {
+	for {
+		old := enabledMask.Load()
+		next := old | uint32(maskOf(levelsFromSlice(levels)))
+		if enabledMask.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
 func TestCallerTagging_DebugfIncludesFunction(t *testing.T) {
 	var buf bytes.Buffer
 	// Replace the Debug logger to capture output
 	Debug = log.New(&buf, "", 0)
-	enabledLevels[DebugLevel] = true
+	enableLevels(DebugLevel)
 	prevInclude := includeCallerTag
 	includeCallerTag = true
 	defer func() { includeCallerTag = prevInclude }()
@@ -28,7 +43,7 @@ func TestCallerTagging_DebugfIncludesFunction(t *testing.T) {
 func TestCallerTagging_DefaultOff(t *testing.T) {
 	var buf bytes.Buffer
 	Info = log.New(&buf, "", 0)
-	enabledLevels[InfoLevel] = true
+	enableLevels(InfoLevel)
 
 	prevInclude := includeCallerTag
 	includeCallerTag = false
@@ -45,7 +60,7 @@ func TestCallerTagging_DefaultOff(t *testing.T) {
 func TestStructuredLogging_InfoKV(t *testing.T) {
 	var buf bytes.Buffer
 	Info = log.New(&buf, "", 0)
-	enabledLevels[InfoLevel] = true
+	enableLevels(InfoLevel)
 
 	InfoKV("test message", "key1", "value1", "key2", 42)
 
@@ -64,7 +79,7 @@ func TestStructuredLogging_InfoKV(t *testing.T) {
 func TestStructuredLogging_ErrorKV(t *testing.T) {
 	var buf bytes.Buffer
 	Error = log.New(&buf, "", 0)
-	enabledLevels[ErrorLevel] = true
+	enableLevels(ErrorLevel)
 
 	ErrorKV("connection failed", "host", "localhost", "port", 5432)
 
@@ -86,12 +101,7 @@ func TestLevelFiltering_DisableDebug(t *testing.T) {
 	Info = log.New(&buf, "", 0)
 
 	// Disable DEBUG level
-	enabledLevels = map[Level]bool{
-		DebugLevel: false,
-		InfoLevel:  true,
-		WarnLevel:  true,
-		ErrorLevel: true,
-	}
+	SetLevels([]Level{InfoLevel, WarnLevel, ErrorLevel})
 
 	Debugf("should not appear")
 	Infof("should appear")
@@ -113,12 +123,7 @@ func TestLevelFiltering_OnlyErrors(t *testing.T) {
 	Error = log.New(&buf, "", 0)
 
 	// Only ERROR level enabled
-	enabledLevels = map[Level]bool{
-		DebugLevel: false,
-		InfoLevel:  false,
-		WarnLevel:  false,
-		ErrorLevel: true,
-	}
+	SetLevels([]Level{ErrorLevel})
 
 	Debugf("debug msg")
 	Infof("info msg")
@@ -200,7 +205,7 @@ func TestEnvironmentLevelFiltering(t *testing.T) {
 func TestCallerInfo_IncludesLineNumber(t *testing.T) {
 	var buf bytes.Buffer
 	Info = log.New(&buf, "", 0)
-	enabledLevels[InfoLevel] = true
+	enableLevels(InfoLevel)
 	prevInclude := includeCallerTag
 	includeCallerTag = true
 	defer func() { includeCallerTag = prevInclude }()
@@ -213,3 +218,597 @@ func TestCallerInfo_IncludesLineNumber(t *testing.T) {
 		t.Fatalf("expected line number in caller info, got: %q", out)
 	}
 }
+
+func TestStructuredLogging_DuplicateKeysKeptByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+
+	InfoKV("x", "a", 1, "a", 2)
+
+	if got := strings.TrimSpace(buf.String()); got != "x a=1 a=2" {
+		t.Fatalf("expected duplicates in call order, got: %q", got)
+	}
+}
+
+func TestStructuredLogging_DedupeFields(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+	prevDedupe := dedupeFields
+	dedupeFields = true
+	defer func() { dedupeFields = prevDedupe }()
+
+	InfoKV("x", "a", 1, "b", 2, "a", 3)
+
+	if got := strings.TrimSpace(buf.String()); got != "x a=3 b=2" {
+		t.Fatalf("expected last value at first position, got: %q", got)
+	}
+}
+
+func TestStructuredLogging_SortFields(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+	prevSort := sortFields
+	sortFields = true
+	defer func() { sortFields = prevSort }()
+
+	InfoKV("x", "c", 3, "a", 1, "b", 2, "a", 0)
+
+	if got := strings.TrimSpace(buf.String()); got != "x a=1 a=0 b=2 c=3" {
+		t.Fatalf("expected fields sorted by key with stable duplicates, got: %q", got)
+	}
+}
+
+func TestStructuredLogging_DedupeAndSortFields(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+	prevDedupe, prevSort := dedupeFields, sortFields
+	dedupeFields, sortFields = true, true
+	defer func() { dedupeFields, sortFields = prevDedupe, prevSort }()
+
+	InfoKV("x", "c", 3, "a", 1, "b", 2, "a", 0)
+
+	if got := strings.TrimSpace(buf.String()); got != "x a=0 b=2 c=3" {
+		t.Fatalf("expected unique sorted keys, got: %q", got)
+	}
+}
+
+func TestMapLogging_InfomSortsKeys(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+
+	Infom("user %s logged in", map[string]any{"ip": "10.0.0.1", "attempt": 2, "device": "mobile"}, "alice")
+
+	want := "user alice logged in attempt=2 device=mobile ip=10.0.0.1"
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Fatalf("expected %q, got: %q", want, got)
+	}
+}
+
+func TestMapLogging_MatchesKVFormat(t *testing.T) {
+	var mapBuf, kvBuf bytes.Buffer
+	enableLevels(ErrorLevel)
+
+	Error = log.New(&mapBuf, "", 0)
+	Errorm("failed", map[string]any{"code": 500, "host": "db"})
+	Error = log.New(&kvBuf, "", 0)
+	ErrorKV("failed", "code", 500, "host", "db")
+
+	if mapBuf.String() != kvBuf.String() {
+		t.Fatalf("map and KV output should match, got %q vs %q", mapBuf.String(), kvBuf.String())
+	}
+}
+
+func TestMapLogging_NilMap(t *testing.T) {
+	var buf bytes.Buffer
+	Warning = log.New(&buf, "", 0)
+	enableLevels(WarnLevel)
+
+	Warnm("disk at %d%%", nil, 91)
+
+	if got := strings.TrimSpace(buf.String()); got != "disk at 91%" {
+		t.Fatalf("expected message without fields, got: %q", got)
+	}
+}
+
+func TestGlobalFields_AppearOnInfof(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout := outStdout
+	defer func() { outStdout = oldStdout }()
+	outStdout = &buf
+
+	Init(Config{Levels: []Level{InfoLevel}, GlobalFields: []any{"service", "checkout", "host", "pod-abc"}})
+	defer SetGlobalFields()
+
+	Infof("ready on port %d", 8080)
+	Api(200, "ok")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got: %q", buf.String())
+	}
+	if lines[0] != "ready on port 8080 service=checkout host=pod-abc" {
+		t.Fatalf("expected global fields on Infof line, got: %q", lines[0])
+	}
+	if lines[1] != "[200] ok service=checkout host=pod-abc" {
+		t.Fatalf("expected global fields on Api line, got: %q", lines[1])
+	}
+}
+
+func TestProcessFields_PIDAndHostname(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout := outStdout
+	defer func() { outStdout = oldStdout }()
+	outStdout = &buf
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skipf("hostname unavailable: %v", err)
+	}
+
+	Init(Config{Levels: []Level{InfoLevel}, IncludePID: true, IncludeHostname: true})
+	defer func() { processFields = nil }()
+	SetGlobalFields("service", "checkout")
+	defer SetGlobalFields()
+
+	Infof("ready")
+
+	want := fmt.Sprintf("ready service=checkout pid=%d host=%s\n", os.Getpid(), host)
+	if got := buf.String(); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestProcessFields_DefaultOff(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout := outStdout
+	defer func() { outStdout = oldStdout }()
+	outStdout = &buf
+
+	Init(Config{Levels: []Level{InfoLevel}})
+	Infof("ready")
+
+	if got := buf.String(); strings.Contains(got, "pid=") || strings.Contains(got, "host=") {
+		t.Fatalf("pid/host should be absent by default, got: %q", got)
+	}
+}
+
+func TestGlobalFields_CallSiteWinsWithDedupe(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+	prevDedupe := dedupeFields
+	dedupeFields = true
+	defer func() { dedupeFields = prevDedupe }()
+
+	SetGlobalFields("service", "checkout", "region", "eu")
+	defer SetGlobalFields()
+
+	InfoKV("override", "region", "us")
+
+	if got := strings.TrimSpace(buf.String()); got != "override region=us service=checkout" {
+		t.Fatalf("expected call-site value to win, got: %q", got)
+	}
+}
+
+// frameworkLog simulates a framework helper that logs on behalf of the application.
+func frameworkLog(msg string) {
+	Infof("%s", msg)
+}
+
+func TestCallerSkipPackages_ResolvesApplicationFrame(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+	prevInclude, prevSkip := includeCallerTag, callerSkipPrefixes
+	includeCallerTag = true
+	defer func() { includeCallerTag, callerSkipPrefixes = prevInclude, prevSkip }()
+
+	callerSkipPrefixes = nil
+	frameworkLog("without skip")
+	callerSkipPrefixes = []string{"github.com/mordilloSan/go-logger/logger.frameworkLog"}
+	frameworkLog("with skip")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got: %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "[logger.frameworkLog:") {
+		t.Fatalf("expected framework frame without skip, got: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "[logger.TestCallerSkipPackages_ResolvesApplicationFrame:") {
+		t.Fatalf("expected application frame with skip, got: %q", lines[1])
+	}
+}
+
+func TestCallerInfo_CacheKeepsDistinctLines(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+	prevInclude := includeCallerTag
+	includeCallerTag = true
+	defer func() { includeCallerTag = prevInclude }()
+
+	for i := 0; i < 2; i++ {
+		Infof("first")
+		Infof("second")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got: %q", buf.String())
+	}
+	tag := func(line string) string { return line[:strings.Index(line, "]")+1] }
+	if tag(lines[0]) == tag(lines[1]) {
+		t.Fatalf("different call sites should have different tags: %q vs %q", lines[0], lines[1])
+	}
+	if tag(lines[0]) != tag(lines[2]) || tag(lines[1]) != tag(lines[3]) {
+		t.Fatalf("cached tags should match the first resolution: %q", lines)
+	}
+}
+
+type codedError struct {
+	code string
+}
+
+func (e codedError) Error() string { return "coded failure" }
+func (e codedError) Code() string  { return e.code }
+
+type codedValue struct{}
+
+func (codedValue) Code() string { return "not-an-error" }
+
+func TestStructuredLogging_ErrorCode(t *testing.T) {
+	var buf bytes.Buffer
+	Error = log.New(&buf, "", 0)
+	enableLevels(ErrorLevel)
+
+	ErrorKV("payment failed", "error", fmt.Errorf("charge: %w", codedError{code: "E_CARD_DECLINED"}))
+
+	want := "payment failed error=charge: coded failure code=E_CARD_DECLINED"
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Fatalf("expected %q, got: %q", want, got)
+	}
+}
+
+func TestStructuredLogging_PlainErrorHasNoCode(t *testing.T) {
+	var buf bytes.Buffer
+	Error = log.New(&buf, "", 0)
+	enableLevels(ErrorLevel)
+
+	ErrorKV("io failed", "error", errors.New("disk full"), "item", codedValue{})
+
+	if got := buf.String(); strings.Contains(got, "code=") {
+		t.Fatalf("plain errors and non-error values should not add a code, got: %q", got)
+	}
+}
+
+func TestStructuredLogging_ExplicitCodeWins(t *testing.T) {
+	var buf bytes.Buffer
+	Error = log.New(&buf, "", 0)
+	enableLevels(ErrorLevel)
+
+	ErrorKV("failed", "code", "CUSTOM", "error", codedError{code: "E_OTHER"})
+
+	if got := buf.String(); strings.Count(got, "code=") != 1 || !strings.Contains(got, "code=CUSTOM") {
+		t.Fatalf("explicit code should be kept alone, got: %q", got)
+	}
+}
+
+type stringerID int
+
+func (s stringerID) String() string { return fmt.Sprintf("id-%d", int(s)) }
+
+type ptrStringer struct{ name string }
+
+func (p *ptrStringer) String() string { return p.name }
+
+func TestEncodeFields_SpecialValues(t *testing.T) {
+	var nilStringer *ptrStringer
+	cases := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"bytes", []byte("hi"), "v=hi"},
+		{"stringer", stringerID(7), "v=id-7"},
+		{"error", errors.New("boom"), "v=boom"},
+		{"nil", nil, "v=<nil>"},
+		{"nil stringer", nilStringer, "v=<nil>"},
+		{"int", 42, "v=42"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := strings.TrimSpace(encodeFields([]Field{{Key: "v", Value: tc.value}})); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestEncodeFields_BytesAsHex(t *testing.T) {
+	prev := bytesAsHex
+	bytesAsHex = true
+	defer func() { bytesAsHex = prev }()
+
+	if got := strings.TrimSpace(encodeFields([]Field{{Key: "body", Value: []byte("hi")}})); got != "body=6869" {
+		t.Fatalf("expected hex bytes, got %q", got)
+	}
+}
+
+func TestEncodeFields_MapsSortedByKey(t *testing.T) {
+	cases := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"string keys", map[string]any{"b": 2, "a": 1, "c": "x"}, "m={a:1 b:2 c:x}"},
+		{"int keys", map[int]string{10: "ten", 2: "two"}, "m={10:ten 2:two}"},
+		{"nested", map[string]any{"z": map[string]int{"y": 1, "x": 2}, "a": []byte("hi")}, "m={a:hi z:{x:2 y:1}}"},
+		{"empty", map[string]int{}, "m={}"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := strings.TrimSpace(encodeFields([]Field{{Key: "m", Value: tc.value}})); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHumanValue_Units(t *testing.T) {
+	cases := []struct {
+		value any
+		want  string
+	}{
+		{Bytes(0), "0B"},
+		{Bytes(1023), "1023B"},
+		{Bytes(1536), "1.5KiB"},
+		{Bytes(5 << 30), "5.0GiB"},
+		{Bytes(-2048), "-2.0KiB"},
+		{Count(999), "999"},
+		{Count(1500), "1.5k"},
+		{Count(2_000_000), "2.0M"},
+		{Count(999_999), "1.0M"},
+		{Count(999_949), "999.9k"},
+		{Bytes(1048575), "1.0MiB"},
+		{Bytes(1023 * 1024), "1023.0KiB"},
+		{Bytes(-1048575), "-1.0MiB"},
+	}
+	for _, tc := range cases {
+		if got, ok := humanValue(tc.value); !ok || got != tc.want {
+			t.Errorf("humanValue(%v) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+	if got := strings.TrimSpace(encodeFields([]Field{{Key: "size", Value: Bytes(2048)}})); got != "size=2048" {
+		t.Fatalf("encodeFields should keep the raw number, got %q", got)
+	}
+}
+
+func TestInfoKV_MapLogsIdenticallyAcrossRuns(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+
+	m := map[string]any{}
+	for i := 0; i < 20; i++ {
+		m[fmt.Sprintf("k%02d", i)] = i
+	}
+	InfoKV("state", "m", m)
+	first := buf.String()
+	for i := 0; i < 50; i++ {
+		buf.Reset()
+		InfoKV("state", "m", m)
+		if buf.String() != first {
+			t.Fatalf("map rendering changed between runs:\n%q\n%q", first, buf.String())
+		}
+	}
+	if !strings.HasPrefix(first, "state m={k00:0 k01:1 k02:2 ") {
+		t.Fatalf("expected sorted keys, got: %q", first)
+	}
+}
+
+func TestMultilineValues_EscapedByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+
+	Init(Config{Levels: AllLevels()})
+	ErrorKV("panic recovered", "stack", "main.go:10\r\nworker.go:42\n", "id", 7)
+
+	want := `panic recovered stack=main.go:10\r\nworker.go:42\n id=7` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("expected escaped newlines on one line:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestMultilineValues_AllowMultilineBlock(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+
+	Init(Config{Levels: AllLevels(), AllowMultiline: true})
+	defer Init(Config{Levels: AllLevels()})
+	InfoKV("config loaded", "yaml", "a: 1\nb: 2\n", "source", "file")
+	InfoKV("single", "k", "v")
+
+	want := "config loaded yaml=<<<\na: 1\nb: 2\n>>> source=file\nsingle k=v\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("expected a delimited block:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestLazyFields_NotEvaluatedWhenDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	Debug = log.New(&buf, "", 0)
+	SetLevels([]Level{InfoLevel})
+	defer SetLevels(AllLevels())
+
+	calls := 0
+	DebugKV("state", "dump", func() any { calls++; return "big" })
+
+	if calls != 0 {
+		t.Fatalf("closure should not run for a disabled level, ran %d times", calls)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no output, got: %q", buf.String())
+	}
+}
+
+func TestLazyFields_EvaluatedOnceWhenEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+	sink := &recordingSink{}
+	sinks = []Sink{sink}
+	defer func() { sinks = nil }()
+
+	calls := 0
+	InfoKV("state",
+		"dump", func() any { calls++; return 42 },
+		"name", func() string { return "alice" })
+
+	if calls != 1 {
+		t.Fatalf("closure should run exactly once, ran %d times", calls)
+	}
+	if got := buf.String(); got != "state dump=42 name=alice\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+	if len(sink.records) != 1 || sink.records[0].Fields[0].Value != 42 {
+		t.Fatalf("sinks should see the resolved value, got %+v", sink.records)
+	}
+}
+
+func TestLazyFields_ClosureMayLog(t *testing.T) {
+	var buf bytes.Buffer
+	Debug = log.New(&buf, "", 0)
+	Info = log.New(&buf, "", 0)
+	enableLevels(DebugLevel, InfoLevel)
+	oldStderr := outStderr
+	defer func() { outStderr = oldStderr }()
+	outStderr = io.Discard
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		InfoKV("outer", "k", func() any { Debugf("inner"); return 1 })
+		Audit("audited", "k", func() any { Debugf("inner audit"); return 2 })
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("logging from a lazy value deadlocked")
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "inner\nouter k=1\ninner audit\n") {
+		t.Fatalf("expected the inner line before the outer one, got: %q", got)
+	}
+}
+
+func TestMaxFields_AtAndOverLimit(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+	maxFields = 3
+	defer func() { maxFields = 0 }()
+
+	InfoKV("at limit", "a", 1, "b", 2, "c", 3)
+	keyvals := make([]any, 0, 2000)
+	for i := 0; i < 1000; i++ {
+		keyvals = append(keyvals, fmt.Sprintf("k%d", i), i)
+	}
+	InfoKV("over limit", keyvals...)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got: %q", buf.String())
+	}
+	if lines[0] != "at limit a=1 b=2 c=3" {
+		t.Fatalf("fields at the limit should be kept, got: %q", lines[0])
+	}
+	if lines[1] != "over limit k0=0 k1=1 k2=2 …(+997 more)" {
+		t.Fatalf("expected first 3 fields and an accurate count, got: %q", lines[1])
+	}
+}
+
+func TestGoroutineID_DistinctAndStable(t *testing.T) {
+	var buf bytes.Buffer
+	Info = log.New(&buf, "", 0)
+	enableLevels(InfoLevel)
+	includeGoroutineID = true
+	defer func() { includeGoroutineID = false }()
+
+	Infof("main")
+	Infof("main again")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Infof("other")
+	}()
+	<-done
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got: %q", buf.String())
+	}
+	ids := make([]string, len(lines))
+	for i, line := range lines {
+		_, id, ok := strings.Cut(line, " goid=")
+		if !ok || id == "" || id == "0" {
+			t.Fatalf("expected a goid field, got: %q", line)
+		}
+		ids[i] = id
+	}
+	if ids[0] != ids[1] {
+		t.Fatalf("same goroutine should keep its id, got %s and %s", ids[0], ids[1])
+	}
+	if ids[0] == ids[2] {
+		t.Fatalf("different goroutines should have different ids, both %s", ids[0])
+	}
+}
+
+func TestLogBeforeInit_WarnsOnce(t *testing.T) {
+	var stderrBuf bytes.Buffer
+	oldStderr := outStderr
+	defer func() { outStderr = oldStderr }()
+	outStderr = &stderrBuf
+	oldInfo, oldError := Info, Error
+	defer func() { Info, Error = oldInfo, oldError }()
+	Info = log.New(io.Discard, "", 0)
+	Error = log.New(io.Discard, "", 0)
+	wasInitialized, wasWarned := initialized.Load(), preInitWarned.Load()
+	defer func() { initialized.Store(wasInitialized); preInitWarned.Store(wasWarned) }()
+	initialized.Store(false)
+	preInitWarned.Store(false)
+	enableLevels(InfoLevel, ErrorLevel)
+
+	Infof("lost")
+	Errorf("also lost")
+
+	if got := stderrBuf.String(); got != "logger: Init not called; logs are discarded\n" {
+		t.Fatalf("expected a single warning, got: %q", got)
+	}
+}
+
+func TestLogBeforeInit_NoWarningForReplacedLogger(t *testing.T) {
+	var stderrBuf, buf bytes.Buffer
+	oldStderr := outStderr
+	defer func() { outStderr = oldStderr }()
+	outStderr = &stderrBuf
+	Info = log.New(&buf, "", 0)
+	wasInitialized, wasWarned := initialized.Load(), preInitWarned.Load()
+	defer func() { initialized.Store(wasInitialized); preInitWarned.Store(wasWarned) }()
+	initialized.Store(false)
+	preInitWarned.Store(false)
+	enableLevels(InfoLevel)
+
+	Infof("kept")
+
+	if stderrBuf.Len() != 0 || buf.String() != "kept\n" {
+		t.Fatalf("a logger with a real writer should not warn, got stderr=%q out=%q", stderrBuf.String(), buf.String())
+	}
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransport_LogsEachRoundTrip(t *testing.T) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = &stderrBuf
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	Init(Config{Levels: AllLevels()})
+	client := &http.Client{Transport: Transport(nil)}
+	for _, path := range []string{"/users?token=secret", "/missing"} {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	okLine := strings.TrimSpace(stdoutBuf.String())
+	if !strings.HasPrefix(okLine, "http request method=GET host="+host+" path=/users status=200 duration_ms=") {
+		t.Fatalf("unexpected INFO line: %q", okLine)
+	}
+	if strings.Contains(okLine, "secret") {
+		t.Fatalf("query string should not be logged: %q", okLine)
+	}
+	if !strings.Contains(stderrBuf.String(), "path=/missing status=404") {
+		t.Fatalf("404 should be logged at WARNING on stderr, got: %q", stderrBuf.String())
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestTransport_LogsFailuresAtError(t *testing.T) {
+	var stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStderr = &stderrBuf
+	setNow(t, time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC))
+
+	Init(Config{Levels: AllLevels()})
+	failing := Transport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))
+	req := &http.Request{Method: http.MethodPost, URL: &url.URL{Scheme: "http", Host: "billing:8080", Path: "/charge"}}
+	if _, err := failing.RoundTrip(req); err == nil {
+		t.Fatal("expected the base transport error")
+	}
+
+	want := "http request method=POST host=billing:8080 path=/charge duration_ms=0 error=connection refused\n"
+	if got := stderrBuf.String(); got != want {
+		t.Fatalf("unexpected error line:\n got %q\nwant %q", got, want)
+	}
+}
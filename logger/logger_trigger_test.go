@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTriggerLevel_ErrorFlushesHeldContext(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+	logPath := filepath.Join(t.TempDir(), "cli.log")
+
+	Init(Config{Levels: AllLevels(), TriggerLevel: ErrorLevel, FilePath: logPath})
+	defer Init(Config{Levels: AllLevels()})
+
+	Debugf("connecting")
+	InfoKV("fetched", "rows", 3)
+	Warnf("slow response")
+	if buf.Len() != 0 {
+		t.Fatalf("lines below the trigger should be held, got: %q", buf.String())
+	}
+
+	Errorf("write failed")
+	Infof("after trigger")
+	Close()
+
+	want := "connecting\nfetched rows=3\nslow response\nwrite failed\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("expected held context before the error:\n got %q\nwant %q", got, want)
+	}
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if strings.Count(string(content), "\n") != 4 || !strings.Contains(string(content), "connecting") {
+		t.Fatalf("file should receive the flushed context, got: %q", content)
+	}
+}
+
+func TestTriggerLevel_CleanRunDiscardsHeldLines(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+	collector := &recordingSink{}
+
+	Init(Config{Levels: AllLevels(), TriggerLevel: ErrorLevel, Sinks: []Sink{collector}})
+	Infof("step one")
+	Warnf("retrying")
+	Close()
+
+	Init(Config{Levels: AllLevels()})
+	Errorf("unrelated")
+
+	if got := buf.String(); got != "unrelated\n" {
+		t.Fatalf("held lines should be discarded on Close, got: %q", got)
+	}
+	if len(collector.records) != 0 {
+		t.Fatalf("sinks should not see discarded lines, got %+v", collector.records)
+	}
+}
+
+func TestTriggerLevel_BufferIsBounded(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+
+	Init(Config{Levels: AllLevels(), TriggerLevel: ErrorLevel, TriggerBufferSize: 2})
+	defer Init(Config{Levels: AllLevels()})
+
+	Infof("one")
+	Infof("two")
+	Infof("three")
+	Errorf("boom")
+
+	if got := buf.String(); got != "two\nthree\nboom\n" {
+		t.Fatalf("expected only the newest held lines, got: %q", got)
+	}
+}
+
+func TestTriggerLevel_FailFastWritesHeldAndExitingLine(t *testing.T) {
+	codes := captureExit(t)
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+
+	Init(Config{Levels: AllLevels(), TriggerLevel: CritLevel, FailFastLevel: ErrorLevel})
+	defer Init(Config{Levels: AllLevels()})
+
+	Infof("context")
+	Errorf("boom")
+
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Fatalf("expected fail-fast exit code 1, got %v", *codes)
+	}
+	if got := buf.String(); got != "context\nboom\n" {
+		t.Fatalf("expected held context then the exiting line, got: %q", got)
+	}
+}
+
+func TestTriggerLevel_ExitWritesHeldRecords(t *testing.T) {
+	codes := captureExit(t)
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+
+	Init(Config{Levels: []Level{InfoLevel}, TriggerLevel: ErrorLevel})
+	defer Init(Config{Levels: AllLevels()})
+
+	Infof("context")
+	Fatalf("fatal level disabled")
+
+	if len(*codes) != 1 {
+		t.Fatalf("expected Fatalf to exit, got %v", *codes)
+	}
+	if got := buf.String(); got != "context\n" {
+		t.Fatalf("held records should be written before exiting, got: %q", got)
+	}
+}
+
+func TestTriggerLevel_SummaryCountsOnlyWrittenLines(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &buf, &buf
+
+	Init(Config{Levels: AllLevels(), TriggerLevel: ErrorLevel, SummaryOnClose: true})
+	defer Init(Config{Levels: AllLevels()})
+
+	Infof("flushed context")
+	Errorf("boom")
+	Infof("held")
+	Warnf("held too")
+	Close()
+
+	want := "flushed context\nboom\nlog summary info=1 error=1\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("summary should be written and skip discarded lines:\n got %q\nwant %q", got, want)
+	}
+}
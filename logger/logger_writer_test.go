@@ -0,0 +1,513 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks every write until release is closed.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (b *blockingWriter) Write(p []byte) (int, error) {
+	<-b.release
+	return len(p), nil
+}
+
+func TestWriteTimeout_BlockingWriterDoesNotDeadlock(t *testing.T) {
+	blocked := &blockingWriter{release: make(chan struct{})}
+	defer close(blocked.release)
+
+	var stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = blocked
+	outStderr = &stderrBuf
+
+	logPath := filepath.Join(t.TempDir(), "timeout.log")
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	Init(Config{
+		Levels:       AllLevels(),
+		FilePath:     logPath,
+		WriteTimeout: 20 * time.Millisecond,
+		OnWriteError: func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		},
+	})
+	defer Close()
+
+	before := DroppedLines()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			Infof("stuck-%d", i)
+		}
+		Errorf("still flowing")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("logging deadlocked behind a blocking writer")
+	}
+
+	if got := DroppedLines() - before; got != 5 {
+		t.Fatalf("expected 5 dropped lines, got %d", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 5 || !errors.Is(errs[0], ErrWriteTimeout) {
+		t.Fatalf("expected 5 ErrWriteTimeout reports, got %v", errs)
+	}
+	if !strings.Contains(stderrBuf.String(), "still flowing") {
+		t.Fatalf("stderr should keep working, got: %q", stderrBuf.String())
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "stuck-4") {
+		t.Fatalf("file sink should still receive lines when stdout hangs, got: %q", content)
+	}
+}
+
+func TestMultiWriter_ContinuesAfterError(t *testing.T) {
+	var first, second bytes.Buffer
+	failing := errWriter{err: errors.New("disk full")}
+	mw := newMultiWriter(&first, failing, &second)
+
+	n, err := mw.Write([]byte("line\n"))
+	if n != 5 {
+		t.Fatalf("expected full length written, got %d", n)
+	}
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected joined error, got %v", err)
+	}
+	if first.String() != "line\n" || second.String() != "line\n" {
+		t.Fatalf("healthy writers should receive the line, got %q and %q", first.String(), second.String())
+	}
+}
+
+type errWriter struct {
+	err error
+}
+
+func (e errWriter) Write(p []byte) (int, error) {
+	return 0, e.err
+}
+
+// flakyWriter fails its first fails writes, then writes to w.
+type flakyWriter struct {
+	w     io.Writer
+	fails int
+}
+
+func (f *flakyWriter) Write(p []byte) (int, error) {
+	if f.fails > 0 {
+		f.fails--
+		return 0, errors.New("transient failure")
+	}
+	return f.w.Write(p)
+}
+
+func TestLevelMirrors_ErrorAppearsInConsoleAndMirror(t *testing.T) {
+	var stderrBuf, mirror bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = io.Discard
+	outStderr = &stderrBuf
+
+	Init(Config{
+		Levels:             AllLevels(),
+		Colorize:           true,
+		IncludeLevelPrefix: true,
+		LevelMirrors:       map[Level]io.Writer{ErrorLevel: &mirror},
+	})
+
+	Warnf("not mirrored")
+	Errorf("mirrored error")
+
+	if !strings.Contains(stderrBuf.String(), "mirrored error") {
+		t.Fatalf("console should still receive the error, got: %q", stderrBuf.String())
+	}
+	got := mirror.String()
+	if !strings.Contains(got, "[ERROR] ") || !strings.Contains(got, "mirrored error") {
+		t.Fatalf("mirror should receive the error line, got: %q", got)
+	}
+	if strings.Contains(got, "not mirrored") {
+		t.Fatalf("mirror should only receive its level, got: %q", got)
+	}
+	if strings.Contains(got, "\033[") {
+		t.Fatalf("mirror output should be plain, got: %q", got)
+	}
+}
+
+func TestLevelMirrors_FailingMirrorKeepsFile(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "main.log")
+
+	Init(Config{
+		Levels:       AllLevels(),
+		FilePath:     logPath,
+		LevelMirrors: map[Level]io.Writer{ErrorLevel: errWriter{err: errors.New("mirror down")}},
+	})
+	defer Close()
+
+	Errorf("still in main file")
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "still in main file") {
+		t.Fatalf("main file should receive the line, got: %q", content)
+	}
+}
+
+func TestOutput_WritesRawBlockToLevelDestinations(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = io.Discard
+	logPath := filepath.Join(t.TempDir(), "report.log")
+
+	Init(Config{Levels: AllLevels(), FilePath: logPath, IncludeLevelPrefix: true})
+	defer Close()
+
+	report := "== report ==\nrow 1\nrow 2\n"
+	n, err := io.WriteString(Output(InfoLevel), report)
+	if err != nil || n != len(report) {
+		t.Fatalf("unexpected write result n=%d err=%v", n, err)
+	}
+
+	if got := stdoutBuf.String(); got != report {
+		t.Fatalf("console should receive the block untouched, got: %q", got)
+	}
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), report) || strings.Contains(string(content), "[INFO]") {
+		t.Fatalf("file should receive the block without a level tag, got: %q", content)
+	}
+}
+
+func TestLineTerminator_CRLF(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = io.Discard
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "app.log")
+
+	Init(Config{Levels: AllLevels(), FilePath: textPath, LineTerminator: "\r\n"})
+	Infof("first")
+	InfoKV("second", "k", "v")
+	Close()
+
+	if got := stdoutBuf.String(); got != "first\r\nsecond k=v\r\n" {
+		t.Fatalf("unexpected console output: %q", got)
+	}
+	content, err := os.ReadFile(textPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if strings.Count(string(content), "\r\n") != 2 || !strings.HasSuffix(string(content), "second k=v\r\n") {
+		t.Fatalf("unexpected file output: %q", content)
+	}
+
+	jsonPath := filepath.Join(dir, "app.json")
+	Init(Config{Levels: AllLevels(), FilePath: jsonPath, Format: FormatJSON, LineTerminator: "\r\n"})
+	Infof("json")
+	Close()
+	Init(Config{Levels: AllLevels()})
+
+	content, err = os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.HasSuffix(string(content), `"msg":"json"}`+"\r\n") {
+		t.Fatalf("unexpected JSON output: %q", content)
+	}
+}
+
+func TestFieldPrefix_CustomSeparator(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	var stdoutBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = io.Discard
+	textPath := filepath.Join(t.TempDir(), "app.log")
+
+	Init(Config{Levels: AllLevels(), FilePath: textPath, FieldPrefix: " | "})
+	InfoKV("request", "path", "/a", "status", 200)
+	Infof("no fields")
+	InfoKV("", "only", "kv")
+	Close()
+	defer Init(Config{Levels: AllLevels()})
+
+	want := "request | path=/a status=200\nno fields\nonly=kv\n"
+	if got := stdoutBuf.String(); got != want {
+		t.Fatalf("unexpected console output: %q", got)
+	}
+	content, err := os.ReadFile(textPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	got := string(content)
+	if !strings.Contains(got, " request | path=/a status=200\n") || !strings.Contains(got, " no fields\n") {
+		t.Fatalf("unexpected file output: %q", got)
+	}
+	if strings.Contains(got, "| only") || strings.Count(got, "|") != 1 {
+		t.Fatalf("prefix should only appear between a message and its fields, got: %q", got)
+	}
+}
+
+func TestLineTerminator_WithoutNewline(t *testing.T) {
+	var stdoutBuf bytes.Buffer
+	oldStdout := outStdout
+	defer func() { outStdout = oldStdout }()
+	outStdout = &stdoutBuf
+
+	Init(Config{Levels: AllLevels(), IncludeLevelPrefix: true, LineTerminator: "\x1e"})
+	defer Init(Config{Levels: AllLevels()})
+	Infof("one")
+	Infof("two")
+
+	if got := stdoutBuf.String(); got != "[INFO] one\x1e[INFO] two\x1e" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func TestLineTerminator_ConcurrentLineCount(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &syncBuffer{buf: &buf}
+	outStderr = io.Discard
+
+	Init(Config{Levels: AllLevels(), LineTerminator: "\r\n"})
+	defer Init(Config{Levels: AllLevels()})
+
+	const goroutines, perGoroutine = 8, 50
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				InfoKV("line", "g", g, "i", i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	if len(lines) != goroutines*perGoroutine {
+		t.Fatalf("expected %d lines, got %d", goroutines*perGoroutine, len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "line g=") || strings.ContainsAny(line, "\r\n") {
+			t.Fatalf("malformed line: %q", line)
+		}
+	}
+}
+
+func TestWithOutput_CapturesOnlyLinesInsideCallback(t *testing.T) {
+	var stdoutBuf, report bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout, outStderr = &stdoutBuf, &stdoutBuf
+	setNow(t, time.Date(2024, 3, 9, 14, 5, 6, 0, time.Local))
+
+	Init(Config{Levels: AllLevels(), IncludeLevelPrefix: true})
+	defer Init(Config{Levels: AllLevels()})
+
+	Infof("before")
+	WithOutput(&report, func() {
+		Infof("migrating %s", "users")
+		ErrorKV("migration failed", "table", "users")
+	})
+	Infof("after")
+
+	want := "2024/03/09 14:05:06 [INFO] migrating users\n" +
+		"2024/03/09 14:05:06 [ERROR] migration failed table=users\n"
+	if got := report.String(); got != want {
+		t.Fatalf("unexpected captured output:\n got %q\nwant %q", got, want)
+	}
+	for _, line := range []string{"before", "migrating users", "after"} {
+		if !strings.Contains(stdoutBuf.String(), line) {
+			t.Fatalf("console should keep every line, missing %q in %q", line, stdoutBuf.String())
+		}
+	}
+}
+
+func TestWithOutput_DetachesAfterPanic(t *testing.T) {
+	defer discardOutput()()
+	var report bytes.Buffer
+	Init(Config{Levels: AllLevels()})
+
+	func() {
+		defer func() { _ = recover() }()
+		WithOutput(&report, func() {
+			Infof("inside")
+			panic("boom")
+		})
+	}()
+	Infof("outside")
+
+	if got := report.String(); !strings.HasSuffix(got, "inside\n") || strings.Contains(got, "outside") {
+		t.Fatalf("writer should be detached after a panic, got: %q", got)
+	}
+}
+
+func TestRouting_PerLevelDestinations(t *testing.T) {
+	var stdoutBuf, stderrBuf, debugBuf, errorBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = &stdoutBuf
+	outStderr = &stderrBuf
+	logPath := filepath.Join(t.TempDir(), "app.log")
+
+	Init(Config{
+		Levels:             AllLevels(),
+		FilePath:           logPath,
+		IncludeLevelPrefix: true,
+		Routing: map[Level]RouteSpec{
+			DebugLevel:  {Dest: RouteWriter, Writer: &debugBuf},
+			ErrorLevel:  {Dest: RouteWriter, Writer: &errorBuf},
+			WarnLevel:   {Dest: RouteStdout},
+			NoticeLevel: {Dest: RouteFile},
+			CritLevel:   {Dest: RouteDiscard},
+		},
+	})
+	defer Init(Config{Levels: AllLevels()})
+
+	Debugf("debug-line")
+	Infof("info-line")
+	Noticef("notice-line")
+	Warnf("warn-line")
+	Errorf("error-line")
+	Critf("crit-line")
+	Alertf("alert-line")
+	Close()
+
+	if got := debugBuf.String(); got != "[DEBUG] debug-line\n" {
+		t.Fatalf("unexpected debug destination output: %q", got)
+	}
+	if got := errorBuf.String(); got != "[ERROR] error-line\n" {
+		t.Fatalf("unexpected error destination output: %q", got)
+	}
+	if got := stdoutBuf.String(); got != "[INFO] info-line\n[WARNING] warn-line\n" {
+		t.Fatalf("unexpected stdout output: %q", got)
+	}
+	if got := stderrBuf.String(); got != "[ALERT] alert-line\n" {
+		t.Fatalf("unrouted levels should keep the default split, got stderr: %q", got)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	for _, want := range []string{"debug-line", "notice-line", "error-line", "alert-line"} {
+		if !strings.Contains(string(content), want) {
+			t.Fatalf("file should contain %q, got: %q", want, content)
+		}
+	}
+	if strings.Contains(string(content), "crit-line") {
+		t.Fatalf("RouteDiscard should skip the file, got: %q", content)
+	}
+}
+
+func TestInit_ClosedStdoutFallsBackToDiscard(t *testing.T) {
+	t.Setenv("JOURNAL_STREAM", "")
+	closed, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatalf("failed to create stdout stand-in: %v", err)
+	}
+	closed.Close()
+
+	var stderrBuf bytes.Buffer
+	oldStdout, oldStderr := outStdout, outStderr
+	defer func() { outStdout, outStderr = oldStdout, oldStderr }()
+	outStdout = closed
+	outStderr = &stderrBuf
+
+	logPath := filepath.Join(t.TempDir(), "fallback.log")
+	var errs []error
+	Init(Config{
+		Levels:       AllLevels(),
+		FilePath:     logPath,
+		OnWriteError: func(err error) { errs = append(errs, err) },
+	})
+	defer Close()
+
+	Infof("after fallback")
+	Errorf("stderr still works")
+
+	if Info.Writer() != io.Discard {
+		t.Fatalf("expected closed stdout to be replaced with io.Discard")
+	}
+	if len(errs) != 0 {
+		t.Fatalf("expected no write errors, got %v", errs)
+	}
+	if !strings.Contains(stderrBuf.String(), "stderr still works") {
+		t.Fatalf("stderr should keep working, got: %q", stderrBuf.String())
+	}
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	got := string(content)
+	if !strings.Contains(got, "stdout unavailable, console output discarded error=") {
+		t.Fatalf("expected fallback notice in file, got: %q", got)
+	}
+	if !strings.Contains(got, "after fallback") {
+		t.Fatalf("file sink should receive lines when stdout is closed, got: %q", got)
+	}
+}
+
+func TestOutput_RespectsRouteDiscard(t *testing.T) {
+	defer discardOutput()()
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	Init(Config{
+		Levels:   AllLevels(),
+		FilePath: logPath,
+		Routing:  map[Level]RouteSpec{DebugLevel: {Dest: RouteDiscard}},
+	})
+	defer Init(Config{Levels: AllLevels()})
+
+	if _, err := io.WriteString(Output(DebugLevel), "discarded raw\n"); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := io.WriteString(Output(InfoLevel), "kept raw\n"); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if got := string(content); got != "kept raw\n" {
+		t.Fatalf("discarded level should not reach the file, got: %q", got)
+	}
+}
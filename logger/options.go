@@ -0,0 +1,42 @@
+package logger
+
+// Option sets one or more Config fields; see NewConfig.
+type Option func(*Config)
+
+// NewConfig builds a Config by applying opts in order to the zero Config, so
+// later options override earlier ones. The result is an ordinary Config:
+// fields without an option can still be set before passing it to Init.
+//
+//	logger.Init(logger.NewConfig(logger.WithLevels(logger.InfoLevel, logger.ErrorLevel), logger.WithColor()))
+func NewConfig(opts ...Option) Config {
+	var config Config
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// WithLevels sets Config.Levels.
+func WithLevels(levels ...Level) Option {
+	return func(c *Config) { c.Levels = levels }
+}
+
+// WithFile sets Config.FilePath.
+func WithFile(path string) Option {
+	return func(c *Config) { c.FilePath = path }
+}
+
+// WithColor sets Config.Colorize.
+func WithColor() Option {
+	return func(c *Config) { c.Colorize = true }
+}
+
+// WithCaller sets Config.IncludeCallerTag.
+func WithCaller() Option {
+	return func(c *Config) { c.IncludeCallerTag = true }
+}
+
+// WithJSON sets Config.Format to FormatJSON.
+func WithJSON() Option {
+	return func(c *Config) { c.Format = FormatJSON }
+}
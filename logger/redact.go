@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// redactRule is a compiled Config.RedactPatterns entry with its replacement
+// template: "***" followed by every capturing group of the match.
+type redactRule struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// redactRules holds Config.RedactPatterns; nil when redaction is off.
+var redactRules []redactRule
+
+// newRedactRules prepares the replacement template of each pattern once.
+func newRedactRules(patterns []*regexp.Regexp) []redactRule {
+	var rules []redactRule
+	for _, re := range patterns {
+		if re == nil {
+			continue
+		}
+		var repl strings.Builder
+		repl.WriteString("***")
+		for i := 1; i <= re.NumSubexp(); i++ {
+			repl.WriteString("${" + strconv.Itoa(i) + "}")
+		}
+		rules = append(rules, redactRule{re: re, repl: repl.String()})
+	}
+	return rules
+}
+
+// redactString applies every rule to s in order.
+func redactString(s string) string {
+	for _, rule := range redactRules {
+		s = rule.re.ReplaceAllString(s, rule.repl)
+	}
+	return s
+}
+
+// redactRecord masks Config.RedactPatterns matches in the message and in the
+// rendered field values. Values with a match are replaced by their redacted
+// text; the others keep their type. Must hold logMutex.
+func redactRecord(rec Record) Record {
+	if len(redactRules) == 0 {
+		return rec
+	}
+	rec.Message = redactString(rec.Message)
+	var fields []Field
+	for i, f := range rec.Fields {
+		text := formatValue(f.Value)
+		redacted := redactString(text)
+		if redacted == text {
+			continue
+		}
+		if fields == nil {
+			fields = append([]Field(nil), rec.Fields...)
+		}
+		fields[i].Value = redacted
+	}
+	if fields != nil {
+		rec.Fields = fields
+	}
+	return rec
+}
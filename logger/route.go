@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"io"
+	"time"
+)
+
+// RouteDest names the console destination of a level in Config.Routing.
+type RouteDest int
+
+const (
+	// RouteStdout writes the level to standard output.
+	RouteStdout RouteDest = iota
+	// RouteStderr writes the level to standard error.
+	RouteStderr
+	// RouteFile writes the level only to the log file (Config.FilePath), not the console.
+	RouteFile
+	// RouteDiscard drops the level from the console and the log file.
+	// LevelMirrors, Sinks and SinkFunc still receive it.
+	RouteDiscard
+	// RouteWriter writes the level to RouteSpec.Writer instead of the console,
+	// e.g. a separate debug file opened by the caller.
+	RouteWriter
+)
+
+// RouteSpec is the destination of one level in Config.Routing.
+type RouteSpec struct {
+	Dest RouteDest
+	// Writer receives the level's lines when Dest is RouteWriter.
+	Writer io.Writer
+}
+
+// routeOutput returns the console writer of route, given the standard streams
+// already wrapped for Config.WriteTimeout.
+func routeOutput(route RouteSpec, stdout, stderr io.Writer, timeout time.Duration) io.Writer {
+	switch route.Dest {
+	case RouteStdout:
+		return stdout
+	case RouteStderr:
+		return stderr
+	case RouteWriter:
+		if route.Writer != nil {
+			return withWriteTimeout(route.Writer, timeout)
+		}
+	}
+	return io.Discard
+}
@@ -0,0 +1,23 @@
+package logger
+
+import "sync/atomic"
+
+var (
+	// includeSequence holds Config.IncludeSequence.
+	includeSequence bool
+
+	// lineSequence numbers emitted records for Config.IncludeSequence. It is
+	// never reset, so numbers stay unique for the life of the process.
+	lineSequence atomic.Uint64
+)
+
+// withSequence appends the next seq=N field to rec when Config.IncludeSequence
+// is set. It runs once per emitted record, after filtering and TriggerLevel
+// buffering, so consecutive lines always carry consecutive numbers.
+func withSequence(rec Record) Record {
+	if !includeSequence {
+		return rec
+	}
+	rec.Fields = append(rec.Fields[:len(rec.Fields):len(rec.Fields)], Field{Key: "seq", Value: lineSequence.Add(1)})
+	return rec
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// HandleSignals flushes and closes the log file when the process receives one of
+// signals (SIGINT and SIGTERM when none are given), so buffered logs are not lost
+// on shutdown.
+//
+// The logger listens on its own channel, so handlers the application registered
+// with signal.Notify still receive the signal. Deciding whether to exit is left
+// to the application; note that a signal handled through signal.Notify no longer
+// terminates the process by default.
+//
+// The returned stop function uninstalls the handler and waits for it to exit.
+func HandleSignals(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-ch:
+				_ = Flush()
+				_ = Close()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+			<-exited
+		})
+	}
+}
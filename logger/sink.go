@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Sink consumes logged records and renders them itself, so several outputs
+// (console text, a JSON file, journald, a network collector) can each format
+// the same record independently. See Config.Sinks.
+type Sink interface {
+	WriteRecord(rec Record) error
+}
+
+// funcSink adapts Config.SinkFunc to Sink, turning a panic into an error so
+// one failing transport cannot break logging for the others.
+type funcSink func(rec Record)
+
+func (f funcSink) WriteRecord(rec Record) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("logger: sink func panicked: %v", r)
+		}
+	}()
+	f(rec)
+	return nil
+}
+
+// textSink writes records as plain FormatText lines: a timestamp, the level
+// prefix, the caller tag, the message and the fields, without colors.
+// Records at or above markLevel are wrapped in Config.SeverityMarkers
+// markers; DebugLevel means no markers.
+type textSink struct {
+	w         io.Writer
+	style     LevelPrefixStyle
+	markLevel Level
+}
+
+func (s *textSink) WriteRecord(rec Record) error {
+	var b strings.Builder
+	marked := s.markLevel != DebugLevel && severity(rec.Level) >= severity(s.markLevel)
+	if marked {
+		b.WriteString(">>> " + levelName(rec.Level) + " ")
+	}
+	b.WriteString(rec.Time.Format(timestampLayout))
+	if tag := levelTag(rec.Level.String(), s.style); tag != "" {
+		b.WriteString(tag + " ")
+	}
+	if rec.Caller != "" {
+		b.WriteString("[" + rec.Caller + "] ")
+	}
+	b.WriteString(messageWithFields(rec.text(), encodeFields(rec.Fields)))
+	if marked {
+		b.WriteString(" <<<")
+	}
+	b.WriteString(lineTerminator)
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+// jsonSink writes records as FormatJSON lines ended by terminator.
+type jsonSink struct {
+	w          io.Writer
+	terminator string
+}
+
+func (s *jsonSink) WriteRecord(rec Record) error {
+	_, err := s.w.Write(append(encodeJSON(rec), s.terminator...))
+	return err
+}
+
+// encodeJSON renders rec as a single JSON object.
+// Keys keep the record order: time, level, caller_func/caller_file/caller_line,
+// status, msg, then the fields. Records with only a Caller tag (e.g. decoded
+// binary frames) write it as "caller".
+func encodeJSON(rec Record) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	appendJSON(&buf, rec.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	appendJSON(&buf, levelName(rec.Level))
+	if rec.CallerFunc != "" {
+		buf.WriteString(`,"caller_func":`)
+		appendJSON(&buf, rec.CallerFunc)
+		buf.WriteString(`,"caller_file":`)
+		appendJSON(&buf, rec.CallerFile)
+		buf.WriteString(`,"caller_line":`)
+		appendJSON(&buf, rec.CallerLine)
+	} else if rec.Caller != "" {
+		buf.WriteString(`,"caller":`)
+		appendJSON(&buf, rec.Caller)
+	}
+	if rec.Status != 0 {
+		buf.WriteString(`,"status":`)
+		appendJSON(&buf, rec.Status)
+	}
+	buf.WriteString(`,"msg":`)
+	appendJSON(&buf, rec.Message)
+	for _, f := range rec.Fields {
+		buf.WriteByte(',')
+		appendJSON(&buf, f.Key)
+		buf.WriteByte(':')
+		appendJSON(&buf, jsonValue(f.Value))
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// jsonValue returns v in a form encoding/json renders the way text output does:
+// errors, fmt.Stringers and []byte become strings rather than objects or base64.
+func jsonValue(v any) any {
+	switch v.(type) {
+	case error, fmt.Stringer, []byte:
+		return formatValue(v)
+	}
+	return v
+}
+
+// appendJSON writes v as JSON without HTML escaping, falling back to its text
+// form when v cannot be marshaled (channels, funcs, NaN).
+func appendJSON(buf *bytes.Buffer, v any) {
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		out.Reset()
+		_ = enc.Encode(formatValue(v))
+	}
+	// Encode terminates each value with a newline.
+	buf.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,258 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"time"
+)
+
+// loggerState is the package state saved by Snapshot.
+type loggerState struct {
+	debug, info, notice, warning, err, crit, alert, emerg, fatal *log.Logger
+
+	initialized, preInitWarned bool
+	enabledMask                uint32
+	muted                      bool
+	leveler                    *levelerHolder
+
+	outStdout, outStderr io.Writer
+	exitFunc             func(int)
+	nowFunc              func() time.Time
+
+	logFile           *os.File
+	logFilePath       string
+	fileBuffer        *bufio.Writer
+	fileBufferOut     io.Writer
+	fileSink          Sink
+	mirrorSinks       map[Level]*textSink
+	fileFieldDenylist map[string]bool
+	fileSkip          levelMask
+	sinks             []Sink
+	teeSinks          []*textSink
+	onWriteError      func(err error)
+	traceExtractor    func(ctx context.Context) (traceID, spanID string)
+
+	auditFile     *os.File
+	auditSequence bool
+	auditSeq      uint64
+
+	includeCallerTag   bool
+	callerSkipPrefixes []string
+	colorLoggers       map[*log.Logger]bool
+	levelColors        map[string]string
+	highlightRules     []HighlightRule
+	colorFields        bool
+	dualTimeZone       *time.Location
+	textPrefixStyle    LevelPrefixStyle
+	lowercaseLevels    bool
+	padLevelPrefix     bool
+	defaultLevel       Level
+	lineTerminator     string
+	fieldPrefix        string
+	allowMultiline     bool
+	strictFormat       bool
+	bytesAsHex         bool
+
+	globalFields       []Field
+	processFields      []Field
+	dedupeFields       bool
+	sortFields         bool
+	includeGoroutineID bool
+	includeSequence    bool
+	maxFields          int
+	redactRules        []redactRule
+
+	failFastLevel  Level
+	fatalExitCode  int
+	exitHandlers   []func()
+	summaryOnClose bool
+	singleThreaded bool
+	levelCounts    [numLevels]uint64
+
+	triggerLevel Level
+	heldRecords  recordRing
+
+	initConfig Config
+}
+
+// Snapshot saves the logger's global state and returns a function that
+// restores it, so tests can install their own configuration without leaking
+// it into later tests:
+//
+//	defer logger.Snapshot()()
+//	logger.Init(logger.Config{Levels: []logger.Level{logger.DebugLevel}})
+//
+// The saved state covers everything Init, SetLevels, SetLeveler, Disable,
+// SetGlobalFields and OnExit change, including the level loggers and the open
+// log and audit files. On restore, log and audit files opened after Snapshot
+// are flushed and closed. An audit file open at Snapshot time is reopened by
+// path if it was closed in between; the log file must still be open.
+// Process-wide counters (DroppedLines and IncludeSequence numbers) keep
+// counting, and a pending TemporaryLevels restore is not cancelled.
+func Snapshot() func() {
+	s := captureState()
+	return func() { restoreState(s) }
+}
+
+func captureState() *loggerState {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	s := &loggerState{
+		debug: Debug, info: Info, notice: Notice, warning: Warning, err: Error,
+		crit: Crit, alert: Alert, emerg: Emerg, fatal: Fatal,
+
+		initialized:   initialized.Load(),
+		preInitWarned: preInitWarned.Load(),
+		enabledMask:   enabledMask.Load(),
+		muted:         muted.Load(),
+		leveler:       leveler.Load(),
+
+		outStdout: outStdout,
+		outStderr: outStderr,
+		exitFunc:  exitFunc,
+		nowFunc:   nowFunc,
+
+		logFile:           logFile,
+		logFilePath:       logFilePath,
+		fileBuffer:        fileBuffer,
+		fileBufferOut:     fileBufferOut,
+		fileSink:          fileSink,
+		mirrorSinks:       mirrorSinks,
+		fileFieldDenylist: fileFieldDenylist,
+		fileSkip:          fileSkip,
+		sinks:             sinks,
+		teeSinks:          slices.Clone(teeSinks),
+		onWriteError:      onWriteError,
+		traceExtractor:    traceExtractor,
+
+		auditFile:     auditFile,
+		auditSequence: auditSequence,
+		auditSeq:      auditSeq,
+
+		includeCallerTag:   includeCallerTag,
+		callerSkipPrefixes: callerSkipPrefixes,
+		colorLoggers:       colorLoggers,
+		levelColors:        levelColors,
+		highlightRules:     highlightRules,
+		colorFields:        colorFields,
+		dualTimeZone:       dualTimeZone,
+		textPrefixStyle:    textPrefixStyle,
+		lowercaseLevels:    lowercaseLevels,
+		padLevelPrefix:     padLevelPrefix,
+		defaultLevel:       defaultLevel,
+		lineTerminator:     lineTerminator,
+		fieldPrefix:        fieldPrefix,
+		allowMultiline:     allowMultiline,
+		strictFormat:       strictFormat.Load(),
+		bytesAsHex:         bytesAsHex,
+
+		globalFields:       globalFields,
+		processFields:      processFields,
+		dedupeFields:       dedupeFields,
+		sortFields:         sortFields,
+		includeGoroutineID: includeGoroutineID,
+		includeSequence:    includeSequence,
+		maxFields:          maxFields,
+		redactRules:        redactRules,
+
+		failFastLevel:  failFastLevel,
+		fatalExitCode:  fatalExitCode,
+		exitHandlers:   slices.Clone(exitHandlers),
+		summaryOnClose: summaryOnClose,
+		singleThreaded: singleThreaded.Load(),
+
+		triggerLevel: triggerLevel,
+		heldRecords:  heldRecords,
+
+		initConfig: initConfig,
+	}
+	s.heldRecords.buf = slices.Clone(heldRecords.buf)
+	for i := range levelCounts {
+		s.levelCounts[i] = levelCounts[i].Load()
+	}
+	return s
+}
+
+func restoreState(s *loggerState) {
+	stopFlusher()
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	if logFile != nil && logFile != s.logFile {
+		flushFileBuffer()
+		_ = logFile.Close()
+	}
+	if auditFile != nil && auditFile != s.auditFile {
+		_ = auditFile.Close()
+	}
+
+	Debug, Info, Notice, Warning, Error = s.debug, s.info, s.notice, s.warning, s.err
+	Crit, Alert, Emerg, Fatal = s.crit, s.alert, s.emerg, s.fatal
+
+	initialized.Store(s.initialized)
+	preInitWarned.Store(s.preInitWarned)
+	enabledMask.Store(s.enabledMask)
+	muted.Store(s.muted)
+	leveler.Store(s.leveler)
+
+	outStdout, outStderr = s.outStdout, s.outStderr
+	exitFunc, nowFunc = s.exitFunc, s.nowFunc
+
+	logFile, logFilePath, fileBuffer, fileSink = s.logFile, s.logFilePath, s.fileBuffer, s.fileSink
+	fileBufferOut = s.fileBufferOut
+	mirrorSinks, fileFieldDenylist, fileSkip = s.mirrorSinks, s.fileFieldDenylist, s.fileSkip
+	sinks, teeSinks = s.sinks, s.teeSinks
+	onWriteError, traceExtractor = s.onWriteError, s.traceExtractor
+
+	auditFile, auditSequence, auditSeq = s.auditFile, s.auditSequence, s.auditSeq
+	if auditFile != nil {
+		// Init and Close close the audit file they replace; reopen it by path.
+		if _, err := auditFile.Stat(); err != nil {
+			auditFile = nil
+			if f, err := openAuditPath(s.initConfig.AuditFilePath); err == nil {
+				auditFile = f
+			} else {
+				fmt.Fprintf(outStderr, "failed to reopen audit file %s: %v\n", s.initConfig.AuditFilePath, err)
+			}
+		}
+	}
+
+	includeCallerTag, callerSkipPrefixes = s.includeCallerTag, s.callerSkipPrefixes
+	colorLoggers, levelColors, highlightRules, colorFields = s.colorLoggers, s.levelColors, s.highlightRules, s.colorFields
+	dualTimeZone, textPrefixStyle = s.dualTimeZone, s.textPrefixStyle
+	lowercaseLevels, padLevelPrefix = s.lowercaseLevels, s.padLevelPrefix
+	defaultLevel, lineTerminator, fieldPrefix = s.defaultLevel, s.lineTerminator, s.fieldPrefix
+	allowMultiline, bytesAsHex = s.allowMultiline, s.bytesAsHex
+	strictFormat.Store(s.strictFormat)
+
+	globalFields, processFields = s.globalFields, s.processFields
+	dedupeFields, sortFields = s.dedupeFields, s.sortFields
+	includeGoroutineID, includeSequence = s.includeGoroutineID, s.includeSequence
+	maxFields, redactRules = s.maxFields, s.redactRules
+
+	failFastLevel, fatalExitCode, exitHandlers = s.failFastLevel, s.fatalExitCode, s.exitHandlers
+	summaryOnClose = s.summaryOnClose
+	singleThreaded.Store(s.singleThreaded)
+	for i := range levelCounts {
+		levelCounts[i].Store(s.levelCounts[i])
+	}
+
+	triggerLevel, heldRecords = s.triggerLevel, s.heldRecords
+
+	initConfig = s.initConfig
+
+	if fileBuffer != nil && initConfig.FlushInterval > 0 {
+		// The flusher's first tick waits for logMutex, released on return.
+		startFlusher(initConfig.FlushInterval)
+	}
+}
@@ -0,0 +1,56 @@
+package logger
+
+import "time"
+
+// Span times an operation started with StartSpan.
+type Span struct {
+	name  string
+	start time.Time
+}
+
+// StartSpan starts timing an operation; call End or EndErr when it finishes.
+//
+// Example:
+//
+//	span := logger.StartSpan("db migrate")
+//	err := migrate()
+//	span.EndErr(err, "tables", 12)
+func StartSpan(name string) *Span {
+	return &Span{name: name, start: nowFunc()}
+}
+
+// End logs the span name at INFO with a duration_ms field (whole milliseconds
+// since StartSpan) followed by keyvals.
+// The caller tag points at the End call site.
+func (s *Span) End(keyvals ...any) {
+	if !isLevelEnabled(InfoLevel) {
+		return
+	}
+	logMessage(InfoLevel, 2, s.name, s.fields(nil, keyvals))
+}
+
+// EndErr is End for operations that can fail: when err is non-nil the span is
+// logged at ERROR with an error field after duration_ms; otherwise it behaves like End.
+func (s *Span) EndErr(err error, keyvals ...any) {
+	if err == nil {
+		if !isLevelEnabled(InfoLevel) {
+			return
+		}
+		logMessage(InfoLevel, 2, s.name, s.fields(nil, keyvals))
+		return
+	}
+	if !isLevelEnabled(ErrorLevel) {
+		return
+	}
+	logMessage(ErrorLevel, 2, s.name, s.fields(err, keyvals))
+}
+
+// fields builds the duration_ms pair, the optional error and the caller's keyvals.
+func (s *Span) fields(err error, keyvals []any) []any {
+	out := make([]any, 0, 4+len(keyvals))
+	out = append(out, "duration_ms", nowFunc().Sub(s.start).Milliseconds())
+	if err != nil {
+		out = append(out, "error", err)
+	}
+	return append(out, keyvals...)
+}
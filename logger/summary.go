@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// levelCounts counts the lines written per level since the last Init. Lines
+// held by Config.TriggerLevel are counted only once they are written.
+var levelCounts [numLevels]atomic.Uint64
+
+// countLine records one logged line at level.
+func countLine(level Level) {
+	if level >= 0 && level < numLevels {
+		levelCounts[level].Add(1)
+	}
+}
+
+// resetCounts zeroes every level count.
+func resetCounts() {
+	for i := range levelCounts {
+		levelCounts[i].Store(0)
+	}
+}
+
+// logSummary logs the Config.SummaryOnClose NOTICE line, e.g.
+// "log summary debug=12 info=340 warning=4 error=1", listing the levels with
+// at least one line in severity order. The line bypasses Config.TriggerLevel,
+// whose held lines Close is about to discard. It must not be called with
+// logMutex held.
+func logSummary() {
+	if !isLevelEnabled(NoticeLevel) {
+		return
+	}
+	var fields []Field
+	for _, level := range AllLevels() {
+		if n := levelCounts[level].Load(); n > 0 {
+			fields = append(fields, Field{Key: strings.ToLower(level.String()), Value: n})
+		}
+	}
+	if !singleThreaded.Load() {
+		logMutex.Lock()
+		defer logMutex.Unlock()
+	}
+	// Frames: buildRecord, logSummary, Close, its caller.
+	emitRecord(buildRecord(NoticeLevel, 3, time.Time{}, 0, "log summary", fields))
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"io"
+	"slices"
+)
+
+// teeSinks are the writers attached by WithOutput callbacks that are still
+// running, rendered as plain text lines. Guarded by logMutex.
+var teeSinks []*textSink
+
+// WithOutput additionally writes all log output to w while fn runs, then
+// detaches w, even if fn panics. Lines are plain text like the log file
+// (timestamp, level and caller tags as configured, no colors), and the
+// existing console, file and Sinks outputs keep receiving everything.
+//
+// The tee is global, not scoped to fn's goroutine: lines logged by other
+// goroutines while fn runs are captured too. Calls may nest or overlap; each
+// writer only sees the lines logged while its own callback runs.
+//
+// Example:
+//
+//	var report bytes.Buffer
+//	logger.WithOutput(&report, func() { err = migrate(db) })
+//	if err != nil {
+//		attach(report.String())
+//	}
+func WithOutput(w io.Writer, fn func()) {
+	tee := &textSink{w: w, style: textPrefixStyle}
+	logMutex.Lock()
+	teeSinks = append(teeSinks, tee)
+	logMutex.Unlock()
+	defer func() {
+		logMutex.Lock()
+		teeSinks = slices.DeleteFunc(teeSinks, func(s *textSink) bool { return s == tee })
+		logMutex.Unlock()
+	}()
+	fn()
+}
@@ -0,0 +1,66 @@
+package logger
+
+// ColorTheme selects the level colors of a colorized console; see Config.ColorTheme.
+type ColorTheme int
+
+const (
+	// ColorThemeDark uses bright colors readable on dark terminal backgrounds.
+	ColorThemeDark ColorTheme = iota
+	// ColorThemeLight uses darker colors readable on light terminal backgrounds.
+	ColorThemeLight
+	// ColorThemeCustom uses Config.LevelColors, falling back to the dark theme
+	// for levels without an entry.
+	ColorThemeCustom
+)
+
+// darkPalette holds the ColorThemeDark colors by level name.
+var darkPalette = map[string]string{
+	"DEBUG":   "\033[36m",
+	"INFO":    "\033[32m",
+	"NOTICE":  "\033[34m",
+	"WARNING": "\033[33m",
+	"ERROR":   "\033[31m",
+	"CRIT":    "\033[91m",
+	"ALERT":   "\033[95m",
+	"EMERG":   "\033[97m",
+	"FATAL":   "\033[35m",
+}
+
+// lightPalette holds the ColorThemeLight colors by level name: 256-color
+// shades dark enough to read on a white background.
+var lightPalette = map[string]string{
+	"DEBUG":   "\033[38;5;24m",
+	"INFO":    "\033[38;5;28m",
+	"NOTICE":  "\033[38;5;19m",
+	"WARNING": "\033[38;5;130m",
+	"ERROR":   "\033[38;5;124m",
+	"CRIT":    "\033[1;38;5;160m",
+	"ALERT":   "\033[38;5;90m",
+	"EMERG":   "\033[1;30m",
+	"FATAL":   "\033[38;5;127m",
+}
+
+// levelColors holds the palette selected by Config.ColorTheme.
+var levelColors = darkPalette
+
+// paletteFor returns the level colors of theme, with custom applied over the
+// dark theme for ColorThemeCustom.
+func paletteFor(theme ColorTheme, custom map[Level]string) map[string]string {
+	switch theme {
+	case ColorThemeLight:
+		return lightPalette
+	case ColorThemeCustom:
+		palette := make(map[string]string, len(darkPalette))
+		for name, color := range darkPalette {
+			palette[name] = color
+		}
+		for level, color := range custom {
+			if severity(level) >= 0 {
+				palette[level.String()] = color
+			}
+		}
+		return palette
+	default:
+		return darkPalette
+	}
+}
@@ -0,0 +1,46 @@
+package logger
+
+import "net/http"
+
+// transport is the http.RoundTripper returned by Transport.
+type transport struct {
+	base http.RoundTripper
+}
+
+// Transport wraps base (http.DefaultTransport when nil) so every outgoing
+// request is logged as one "http request" line with method, host, path,
+// status and duration_ms fields. The level follows the status code like Api
+// (2xx/3xx INFO, 4xx WARNING, 5xx ERROR); a failed round-trip is logged at
+// ERROR with an error field instead of status.
+//
+// Query strings and headers are never logged, so tokens in them cannot leak;
+// Config.RedactPatterns still applies to the logged path.
+//
+// Example:
+//
+//	client := &http.Client{Transport: logger.Transport(nil)}
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := nowFunc()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := nowFunc().Sub(start).Milliseconds()
+
+	keyvals := []any{"method", req.Method, "host", req.URL.Host, "path", req.URL.Path}
+	if err != nil {
+		if isLevelEnabled(ErrorLevel) {
+			logMessage(ErrorLevel, 2, "http request", append(keyvals, "duration_ms", elapsed, "error", err))
+		}
+		return resp, err
+	}
+	level := statusCodeToLevel(resp.StatusCode)
+	if isLevelEnabled(level) {
+		logMessage(level, 2, "http request", append(keyvals, "status", resp.StatusCode, "duration_ms", elapsed))
+	}
+	return resp, nil
+}
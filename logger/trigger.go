@@ -0,0 +1,79 @@
+package logger
+
+// defaultTriggerBufferSize is the number of held records kept when
+// Config.TriggerBufferSize is zero.
+const defaultTriggerBufferSize = 256
+
+var (
+	// triggerLevel holds Config.TriggerLevel; DebugLevel means off.
+	triggerLevel = DebugLevel
+	// heldRecords keeps records below triggerLevel until a trigger. Guarded by logMutex.
+	heldRecords recordRing
+)
+
+// recordRing is a bounded FIFO of records that drops the oldest record when full.
+type recordRing struct {
+	buf   []Record
+	start int
+	n     int
+}
+
+func newRecordRing(size int) recordRing {
+	return recordRing{buf: make([]Record, size)}
+}
+
+func (r *recordRing) push(rec Record) {
+	if len(r.buf) == 0 {
+		return
+	}
+	if r.n == len(r.buf) {
+		r.buf[r.start] = rec
+		r.start = (r.start + 1) % len(r.buf)
+		return
+	}
+	r.buf[(r.start+r.n)%len(r.buf)] = rec
+	r.n++
+}
+
+// drain returns the held records oldest first and empties the ring.
+func (r *recordRing) drain() []Record {
+	out := make([]Record, 0, r.n)
+	for i := 0; i < r.n; i++ {
+		idx := (r.start + i) % len(r.buf)
+		out = append(out, r.buf[idx])
+		r.buf[idx] = Record{}
+	}
+	r.start, r.n = 0, 0
+	return out
+}
+
+// reset discards every held record.
+func (r *recordRing) reset() {
+	clear(r.buf)
+	r.start, r.n = 0, 0
+}
+
+// holdRecord reports whether rec was held back by Config.TriggerLevel. When rec
+// is at or above the trigger, or will end the process through
+// Config.FailFastLevel, the held records are written first, oldest first.
+// Must hold logMutex.
+func holdRecord(rec Record) bool {
+	if triggerLevel == DebugLevel {
+		return false
+	}
+	if severity(rec.Level) < severity(triggerLevel) && !failFast(rec.Level) {
+		heldRecords.push(rec)
+		return true
+	}
+	releaseHeldRecords()
+	return false
+}
+
+// releaseHeldRecords writes every held record, oldest first, so exiting the
+// process does not lose the context kept for Config.TriggerLevel.
+// Must hold logMutex.
+func releaseHeldRecords() {
+	for _, held := range heldRecords.drain() {
+		emitRecord(held)
+	}
+}
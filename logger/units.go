@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+)
+
+// ByteSize is a field value holding a number of bytes; see Bytes.
+type ByteSize int64
+
+// Quantity is a field value holding a plain count; see Count.
+type Quantity int64
+
+// Bytes wraps n so the console shows it humanized with binary units
+// (size=1.0MiB), while files, JSON, CSV and Sinks keep the raw integer.
+//
+// Example:
+//
+//	logger.InfoKV("upload done", "size", logger.Bytes(1048576))
+func Bytes(n int64) ByteSize {
+	return ByteSize(n)
+}
+
+// Count wraps n so the console shows it humanized with SI suffixes
+// (rows=1.5M), while files, JSON, CSV and Sinks keep the raw integer.
+func Count(n int64) Quantity {
+	return Quantity(n)
+}
+
+// humanValue renders v for the console when it is a ByteSize or Quantity.
+// ok is false for every other value, which keeps its formatValue form.
+func humanValue(v any) (text string, ok bool) {
+	switch val := v.(type) {
+	case ByteSize:
+		return humanize(int64(val), 1024, "B", []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}), true
+	case Quantity:
+		return humanize(int64(val), 1000, "", []string{"k", "M", "G", "T", "P", "E"}), true
+	}
+	return "", false
+}
+
+// humanize scales n by base until it drops below base and appends the
+// matching unit with one decimal, e.g. 1536 -> "1.5KiB". The unit is chosen
+// after rounding, so 999999 becomes "1.0M" rather than "1000.0k". Values
+// below base are written whole with unit appended.
+func humanize(n int64, base float64, unit string, units []string) string {
+	if math.Abs(float64(n)) < base {
+		return fmt.Sprintf("%d%s", n, unit)
+	}
+	v, i := float64(n)/base, 0
+	for math.Abs(math.Round(v*10)/10) >= base && i < len(units)-1 {
+		v /= base
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", v, units[i])
+}
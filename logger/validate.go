@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Validate reports configuration mistakes that Init would otherwise ignore or
+// degrade silently: unknown levels, formats and prefix styles, negative sizes
+// and durations, options that have no effect together, and a missing or
+// read-only directory for FilePath or AuditFilePath. All problems are
+// returned joined with errors.Join; a valid config returns nil.
+func (c Config) Validate() error {
+	var errs []error
+	for _, level := range c.Levels {
+		if severity(level) < 0 {
+			errs = append(errs, fmt.Errorf("logger: Levels contains unknown level %d", int(level)))
+		}
+	}
+	if c.DefaultLevel != nil && severity(*c.DefaultLevel) < 0 {
+		errs = append(errs, fmt.Errorf("logger: DefaultLevel is an unknown level %d", int(*c.DefaultLevel)))
+	}
+	for name, level := range map[string]Level{
+		"FailFastLevel":       c.FailFastLevel,
+		"StderrThreshold":     c.StderrThreshold,
+		"TriggerLevel":        c.TriggerLevel,
+		"SeverityMarkerLevel": c.SeverityMarkerLevel,
+	} {
+		if severity(level) < 0 {
+			errs = append(errs, fmt.Errorf("logger: %s is an unknown level %d", name, int(level)))
+		}
+	}
+	if strings.HasPrefix(c.Format.String(), "Format(") {
+		errs = append(errs, fmt.Errorf("logger: unknown Format %d", int(c.Format)))
+	}
+	if strings.HasPrefix(c.LevelPrefixStyle.String(), "LevelPrefixStyle(") {
+		errs = append(errs, fmt.Errorf("logger: unknown LevelPrefixStyle %d", int(c.LevelPrefixStyle)))
+	}
+
+	if strings.HasPrefix(c.ColorTheme.String(), "ColorTheme(") {
+		errs = append(errs, fmt.Errorf("logger: unknown ColorTheme %d", int(c.ColorTheme)))
+	}
+	if len(c.LevelColors) > 0 && c.ColorTheme != ColorThemeCustom {
+		errs = append(errs, errors.New("logger: LevelColors requires ColorThemeCustom"))
+	}
+	if c.MaxFields < 0 {
+		errs = append(errs, fmt.Errorf("logger: MaxFields must not be negative, got %d", c.MaxFields))
+	}
+	if c.TriggerBufferSize < 0 {
+		errs = append(errs, fmt.Errorf("logger: TriggerBufferSize must not be negative, got %d", c.TriggerBufferSize))
+	}
+	if c.WriteTimeout < 0 {
+		errs = append(errs, fmt.Errorf("logger: WriteTimeout must not be negative, got %s", c.WriteTimeout))
+	}
+	if c.FlushInterval < 0 {
+		errs = append(errs, fmt.Errorf("logger: FlushInterval must not be negative, got %s", c.FlushInterval))
+	}
+
+	if c.Header != nil && c.Format != FormatText {
+		errs = append(errs, fmt.Errorf("logger: Header is only written by the text format, not %s", c.Format))
+	}
+	if c.SeverityMarkers && c.Format != FormatText {
+		errs = append(errs, fmt.Errorf("logger: SeverityMarkers are only written by the text format, not %s", c.Format))
+	}
+	if c.SingleThreaded && c.FlushInterval > 0 {
+		errs = append(errs, errors.New("logger: SingleThreaded cannot be combined with FlushInterval, whose flusher writes concurrently"))
+	}
+	if c.ColorFields && !c.Colorize {
+		errs = append(errs, errors.New("logger: ColorFields requires Colorize"))
+	}
+	if len(c.Highlights) > 0 && !c.Colorize {
+		errs = append(errs, errors.New("logger: Highlights requires Colorize"))
+	}
+
+	for level, route := range c.Routing {
+		switch {
+		case severity(level) < 0:
+			errs = append(errs, fmt.Errorf("logger: Routing contains unknown level %d", int(level)))
+		case route.Dest < RouteStdout || route.Dest > RouteWriter:
+			errs = append(errs, fmt.Errorf("logger: Routing for %s has unknown destination %d", level, int(route.Dest)))
+		case route.Dest == RouteWriter && route.Writer == nil:
+			errs = append(errs, fmt.Errorf("logger: Routing for %s uses RouteWriter without a Writer", level))
+		case route.Dest == RouteFile && c.FilePath == "":
+			errs = append(errs, fmt.Errorf("logger: Routing for %s uses RouteFile without FilePath", level))
+		}
+	}
+
+	if c.FilePath != "" {
+		errs = append(errs, checkLogDir("FilePath", c.FilePath))
+	}
+	if c.AuditFilePath != "" {
+		errs = append(errs, checkLogDir("AuditFilePath", c.AuditFilePath))
+	}
+	return errors.Join(errs...)
+}
+
+// checkLogDir reports whether the directory of path exists and is writable,
+// judged by its permission bits.
+func checkLogDir(field, path string) error {
+	dir := filepath.Dir(path)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("logger: %s directory %q: %w", field, dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("logger: %s directory %q is not a directory", field, dir)
+	}
+	if info.Mode().Perm()&0222 == 0 {
+		return fmt.Errorf("logger: %s directory %q is not writable", field, dir)
+	}
+	return nil
+}
+
+// InitE validates config and initializes the logger only when it is valid.
+// On error the current configuration stays in place.
+func InitE(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	Init(config)
+	return nil
+}
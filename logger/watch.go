@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// WatchConfig polls the JSON config file at path every interval and applies
+// changes while the program runs, so verbosity can be changed by editing the file.
+//
+// Level changes are applied with SetLevels. If file_path changed, the current
+// log file is closed and the logger is reinitialized with the keys from the
+// file applied over the Config of the last Init, so options missing from the
+// file and fields that cannot be loaded from JSON (Sinks, RedactPatterns,
+// OnWriteError, ...) are kept; otherwise outputs are left untouched. Other
+// goroutines may keep logging during a reload. A change is applied only once
+// the file's modification time has been stable for one interval, which
+// debounces editors that write in several steps. Each reload is logged at
+// NOTICE; a config that fails to load is logged at ERROR and the current
+// settings are kept.
+//
+// The returned stop function ends the watcher and waits for it to exit.
+func WatchConfig(path string, interval time.Duration) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("logger: watch config: interval must be positive, got %s", interval)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("logger: watch config: %w", err)
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		applied := info.ModTime()
+		var pending time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			mtime := info.ModTime()
+			if mtime.Equal(applied) {
+				pending = time.Time{}
+				continue
+			}
+			if !mtime.Equal(pending) {
+				pending = mtime
+				continue
+			}
+			applied = mtime
+			pending = time.Time{}
+			reloadConfig(path)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}, nil
+}
+
+// reloadConfig applies the config at path as described on WatchConfig.
+func reloadConfig(path string) {
+	logMutex.Lock()
+	current, currentPath := initConfig, logFilePath
+	logMutex.Unlock()
+
+	config, err := loadConfigOnto(path, current)
+	if err != nil {
+		Errorf("config reload failed: %v", err)
+		return
+	}
+	pathChanged := config.FilePath != currentPath
+
+	if pathChanged {
+		_ = Close()
+		Init(config)
+	} else {
+		SetLevels(config.Levels)
+	}
+	Noticef("config reloaded from %s", path)
+}